@@ -16,6 +16,8 @@ Created on 20/03/2021
 package serve
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 	"github.com/w6d-io/x/toolx"
 	"strconv"
@@ -24,7 +26,9 @@ import (
 	"github.com/w6d-io/x/pflagx"
 
 	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
 	"github.com/w6d-io/process-rest/pkg/handler"
+	handlerprocess "github.com/w6d-io/process-rest/pkg/handler/process"
 	"github.com/w6d-io/process-rest/pkg/router"
 )
 
@@ -46,11 +50,27 @@ func init() {
 	}
 	pflagx.CallerSkip = callSkip
 	pflagx.Init(Cmd, &config.CfgFile)
+	config.BindFlags(Cmd.Flags())
 }
 
 func serve(_ *cobra.Command, _ []string) error {
 	log := logx.WithName(nil, "Serve.Command")
 
+	executor := process.NewExecutor(config.GetSnapshot, process.NewMemoryRegistry())
+	defer executor.Close()
+	if err := executor.PruneWorkspaces(); err != nil {
+		log.Error(err, "prune workspaces")
+	}
+	if err := executor.PruneArtifacts(); err != nil {
+		log.Error(err, "prune artifacts")
+	}
+	handlerprocess.NewHandler(executor).AddRoutes()
+	router.SetListen(config.GetSnapshot().GetListen())
+	go func() {
+		if err := config.Watch(context.Background()); err != nil {
+			log.Error(err, "watch configuration")
+		}
+	}()
 	if err := router.Run(); err != nil {
 		log.Error(err, "run server")
 		return err
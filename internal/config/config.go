@@ -18,13 +18,12 @@ Created on 20/03/2021
 package config
 
 import (
-	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-
-	"github.com/w6d-io/hook"
-	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"time"
 
 	"github.com/w6d-io/x/cmdx"
 	"github.com/w6d-io/x/logx"
@@ -50,148 +49,223 @@ var (
 // Init load the config file
 func Init() {
 	log := logx.WithName(nil, "Config.Init")
-	config = new(Config)
-	data, err := os.ReadFile(CfgFile)
-	if err != nil {
+	c, err := read(CfgFile)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
 		log.Error(err, "error reading the configuration")
 		OsExit(2)
 		return
 	}
-	err = yaml.Unmarshal(data, config)
-	cmdx.Must(err, "error unmarshal the configuration")
-
-	err = yaml.Unmarshal(data, config)
-	cmdx.Must(err, "Error unmarshal the configuration")
-
-	err = config.AddPreScript()
-	cmdx.Must(err, "Error checking AddPreScript")
-
-	err = config.AddProcessScript()
-	cmdx.Must(err, "Error checking AddProcessScript")
+	cmdx.Must(err, "Error loading the configuration")
+	if err != nil {
+		return
+	}
 
-	err = config.AddPostScript()
-	cmdx.Must(err, "Error checking AddPostScript")
+	s := newSnapshot(c)
+	err = s.loadFolders()
+	cmdx.Must(err, "Error checking the script folders")
 
-	if !Validate() {
-		log.Error(errors.New("a process script should be set"), "")
+	if err := s.validate(); err != nil {
+		log.Error(err, "invalid configuration")
 		OsExit(2)
 		return
 	}
-	for _, wh := range config.Hooks {
-		if err := hook.Subscribe(context.Background(), wh.URL, wh.Scope); err != nil {
-			log.Error(err, "hook subscription failed")
-			OsExit(2)
-			return
-		}
+	if err := s.activate(); err != nil {
+		log.Error(err, "hook subscription failed")
+		OsExit(2)
+		return
 	}
+	log.Info("configuration loaded", "revision", s.Revision)
+}
+
+// GetPreScript returns the pre scripts
+func (s *Snapshot) GetPreScript() []string {
+	return s.preScript
+}
+
+// GetMainScript returns the main scripts
+func (s *Snapshot) GetMainScript() []string {
+	return s.mainScript
+}
+
+// GetPostScript returns the post scripts
+func (s *Snapshot) GetPostScript() []string {
+	return s.postScript
+}
+
+// GetFinallyScript returns the scripts run whatever the outcome of the job
+func (s *Snapshot) GetFinallyScript() []string {
+	return s.finallyScript
+}
+
+// IsHookLegacyLog returns whether the hook payload holds the legacy log message
+func (s *Snapshot) IsHookLegacyLog() bool {
+	return s.config.HookLegacyLog
 }
 
-func (c *Config) AddPostScript() error {
-	log := logx.WithName(nil, "Config.AddPostScript")
-	if c.PostScriptFolder == "" {
-		return nil
+// GetTimeout returns the job timeout
+func (s *Snapshot) GetTimeout() time.Duration {
+	return s.config.Timeout
+}
+
+// GetScriptTimeout returns the script timeout
+func (s *Snapshot) GetScriptTimeout() time.Duration {
+	return s.config.ScriptTimeout
+}
+
+// GetListen returns the address the server listens on
+func (s *Snapshot) GetListen() string {
+	if s.config.Listen == "" {
+		return DefaultListen
 	}
-	files, err := os.ReadDir(c.PostScriptFolder)
-	if err != nil {
-		log.Error(err, "get file in folder failed", "folder", c.PostScriptFolder)
-		return err
+	return s.config.Listen
+}
+
+// GetConcurrency returns the number of jobs run at the same time
+func (s *Snapshot) GetConcurrency() int {
+	if s.config.Concurrency <= 0 {
+		return DefaultConcurrency
 	}
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-		path := fmt.Sprintf("%s%c%s", c.PostScriptFolder, os.PathSeparator, file.Name())
-		AddPostScript(path)
+	return s.config.Concurrency
+}
+
+// GetQueueSize returns the number of jobs waiting for a free slot
+func (s *Snapshot) GetQueueSize() int {
+	if s.config.QueueSize <= 0 {
+		return DefaultQueueSize
 	}
-	return nil
+	return s.config.QueueSize
 }
 
-func (c *Config) AddPreScript() error {
-	log := logx.WithName(nil, "Config.AddPreScript")
-	if c.PreScriptFolder == "" {
-		return nil
+// GetLogBufferSize returns the number of script output lines kept per job
+func (s *Snapshot) GetLogBufferSize() int {
+	if s.config.LogBufferSize <= 0 {
+		return DefaultLogBufferSize
 	}
-	files, err := os.ReadDir(c.PreScriptFolder)
-	if err != nil {
-		log.Error(err, "get file in folder failed", "folder", c.PreScriptFolder)
-		return err
+	return s.config.LogBufferSize
+}
+
+// GetJobRetention returns how long the finished jobs are kept
+func (s *Snapshot) GetJobRetention() time.Duration {
+	if s.config.JobRetention <= 0 {
+		return DefaultJobRetention
 	}
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-		path := fmt.Sprintf("%s%c%s", c.PreScriptFolder, os.PathSeparator, file.Name())
-		AddPreScript(path)
+	return s.config.JobRetention
+}
+
+// GetMaxFinishedJobs returns the number of finished jobs kept
+func (s *Snapshot) GetMaxFinishedJobs() int {
+	if s.config.MaxFinishedJobs <= 0 {
+		return DefaultMaxFinishedJobs
 	}
-	return nil
+	return s.config.MaxFinishedJobs
 }
 
-func (c *Config) AddProcessScript() error {
-	log := logx.WithName(nil, "Config.AddMainScript")
-	if c.MainScriptFolder == "" {
-		return nil
+// GetLockKeyPath returns the path into the payload of the lock key
+func (s *Snapshot) GetLockKeyPath() string {
+	return s.config.LockKeyPath
+}
+
+// GetPayloadModes returns the ways the payload is delivered to the scripts,
+// the YAML file only when not set
+func (s *Snapshot) GetPayloadModes() []string {
+	if len(s.config.PayloadModes) == 0 {
+		return []string{PayloadModeFile}
 	}
-	files, err := os.ReadDir(c.MainScriptFolder)
-	if err != nil {
-		log.Error(err, "get file in folder failed", "folder", c.MainScriptFolder)
-		return err
+	return s.config.PayloadModes
+}
+
+// HasPayloadMode returns whether the payload is delivered with the mode
+func (s *Snapshot) HasPayloadMode(mode string) bool {
+	modes := s.config.PayloadModes
+	if len(modes) == 0 {
+		modes = []string{PayloadModeFile}
 	}
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPayloadModes returns an error on unknown payload mode
+func checkPayloadModes(modes []string) error {
+	for _, m := range modes {
+		switch m {
+		case PayloadModeFile, PayloadModeEnv, PayloadModeStdin, PayloadModeJSON:
+		default:
+			return fmt.Errorf("unknown payload mode %q", m)
 		}
-		path := fmt.Sprintf("%s%c%s", c.MainScriptFolder, os.PathSeparator, file.Name())
-		AddMainScript(path)
 	}
 	return nil
 }
 
-// AddPostScript appends the path to post script
-func AddPostScript(path string) {
-	if path == "" {
-		return
+// GetPayloadEnvPrefix returns the prefix of the payload environment variables
+func (s *Snapshot) GetPayloadEnvPrefix() string {
+	if s.config.PayloadEnvPrefix == "" {
+		return DefaultPayloadEnvPrefix
 	}
-	postScript = append(postScript, path)
+	return s.config.PayloadEnvPrefix
 }
 
-// AddPreScript appends the path to pre script
-func AddPreScript(path string) {
-	if path == "" {
-		return
+// GetInterpreter returns the interpreter of the scripts, bash when not set
+func (s *Snapshot) GetInterpreter() string {
+	if s.config.Interpreter == "" {
+		return InterpreterBash
 	}
-	preScript = append(preScript, path)
+	return s.config.Interpreter
 }
 
-// AddMainScript appends the path to pre script
-func AddMainScript(path string) {
-	if path == "" {
-		return
+// GetInterpreterCommand returns the command running the scripts with the
+// interpreter, bash when empty and none for the shebang
+func GetInterpreterCommand(interpreter string) (string, error) {
+	switch interpreter {
+	case InterpreterShebang:
+		return "", nil
+	case "", InterpreterBash:
+		return "bash", nil
+	case InterpreterSh:
+		return "sh", nil
+	case InterpreterPython:
+		return "python3", nil
 	}
-	mainScript = append(mainScript, path)
+	return "", fmt.Errorf("unknown interpreter %q", interpreter)
 }
 
-func Reset() {
-	preScript = []string{}
-	mainScript = []string{}
-	postScript = []string{}
+// GetWorkspaceDir returns the folder of the job workspaces, the temp dir when not set
+func (s *Snapshot) GetWorkspaceDir() string {
+	if s.config.WorkspaceDir == "" {
+		return os.TempDir()
+	}
+	return s.config.WorkspaceDir
 }
 
-func Validate() bool {
-	log := logx.WithName(nil, "Config.Validate")
-	log.V(1).Info("contain", "pre_script", preScript,
-		"main_script", mainScript,
-		"post_script", postScript)
-	return len(mainScript) != 0
+// GetWorkspaceRetention returns how long the workspace of a failed job is kept
+func (s *Snapshot) GetWorkspaceRetention() time.Duration {
+	return s.config.WorkspaceRetention
 }
 
-func GetPreScript() []string {
-	return preScript
+// GetArtifactDir returns the folder of the job artifacts
+func (s *Snapshot) GetArtifactDir() string {
+	if s.config.ArtifactDir == "" {
+		return filepath.Join(os.TempDir(), "process-rest.artifacts")
+	}
+	return s.config.ArtifactDir
 }
 
-func GetMainScript() []string {
-	return mainScript
+// GetArtifactMaxSize returns the maximum size in bytes of the artifacts of a job
+func (s *Snapshot) GetArtifactMaxSize() int64 {
+	if s.config.ArtifactMaxSize <= 0 {
+		return DefaultArtifactMaxSize
+	}
+	return s.config.ArtifactMaxSize
 }
 
-func GetPostScript() []string {
-	return postScript
+// GetArtifactRetention returns how long the artifacts are kept
+func (s *Snapshot) GetArtifactRetention() time.Duration {
+	if s.config.ArtifactRetention <= 0 {
+		return DefaultArtifactRetention
+	}
+	return s.config.ArtifactRetention
 }
@@ -18,6 +18,7 @@ package config_test
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/w6d-io/process-rest/internal/config"
 
@@ -70,6 +71,13 @@ var _ = Describe("Config", func() {
 				config.Init()
 				Expect(cmdExitCode).To(Equal(1))
 			})
+			It("fail override", func() {
+				config.CfgFile = ""
+				Expect(os.Setenv("PROCESS_REST_CONCURRENCY", "many")).To(Succeed())
+				defer func() { Expect(os.Unsetenv("PROCESS_REST_CONCURRENCY")).To(Succeed()) }()
+				config.Init()
+				Expect(cmdExitCode).To(Equal(1))
+			})
 			It("pre script folder does not exist", func() {
 				config.CfgFile = "testdata/pre_script_does_not_exists.yaml"
 				config.Init()
@@ -100,7 +108,6 @@ var _ = Describe("Config", func() {
 				config.CfgFile = configFile
 				config.Init()
 				Expect(configExitCode).To(Equal(2))
-				config.Reset()
 				err = os.RemoveAll(dir)
 				Expect(err).To(Succeed())
 
@@ -119,7 +126,6 @@ var _ = Describe("Config", func() {
 				Expect(err).To(Succeed())
 				config.CfgFile = configFile
 				config.Init()
-				config.Reset()
 				err = os.RemoveAll(dir)
 				Expect(err).To(Succeed())
 			})
@@ -137,137 +143,116 @@ var _ = Describe("Config", func() {
 				Expect(err).To(Succeed())
 				config.CfgFile = configFile
 				config.Init()
-				config.Reset()
 				err = os.RemoveAll(dir)
 				Expect(err).To(Succeed())
 			})
-			It("failed on hook", func() {
+			It("success with timeouts", func() {
 				dir, err := os.MkdirTemp("", "process_dir")
 				Expect(err).To(Succeed())
-				_, err = os.CreateTemp(dir, "directory")
-				Expect(err).To(Succeed())
 				filename := dir + string(os.PathSeparator) + "script1.sh"
 				err = os.WriteFile(filename, []byte(fileTest), 0644)
 				Expect(err).To(Succeed())
 				configFile := dir + string(os.PathSeparator) + "config.yaml"
-				data := fmt.Sprintf(configTestFileWithHook, "main_script_folder", dir, "http://{}")
+				data := fmt.Sprintf(configTestFile, "main_script_folder", dir) + "timeout: 10m\nscript_timeout: 30s\n"
 				err = os.WriteFile(configFile, []byte(data), 0444)
 				Expect(err).To(Succeed())
 				config.CfgFile = configFile
 				config.Init()
-				Expect(configExitCode).To(Equal(2))
-				config.Reset()
+				Expect(config.GetSnapshot().GetTimeout()).To(Equal(10 * time.Minute))
+				Expect(config.GetSnapshot().GetScriptTimeout()).To(Equal(30 * time.Second))
 				err = os.RemoveAll(dir)
 				Expect(err).To(Succeed())
 			})
-		})
-		Context("add script", func() {
-			BeforeEach(func() {
-			})
-			AfterEach(func() {
-			})
-			It("succeed for postscript", func() {
-				dir, err := os.MkdirTemp("", "post_dir")
-				Expect(err).To(Succeed())
-				_, err = os.CreateTemp(dir, "directory")
+			It("failed on unknown payload mode", func() {
+				dir, err := os.MkdirTemp("", "process_dir")
 				Expect(err).To(Succeed())
 				filename := dir + string(os.PathSeparator) + "script1.sh"
 				err = os.WriteFile(filename, []byte(fileTest), 0644)
 				Expect(err).To(Succeed())
-				c := &config.Config{
-					PostScriptFolder: dir,
-				}
-				err = c.AddPostScript()
+				configFile := dir + string(os.PathSeparator) + "config.yaml"
+				data := fmt.Sprintf(configTestFile, "main_script_folder", dir) + "payload_modes: [env, socket]\n"
+				err = os.WriteFile(configFile, []byte(data), 0444)
 				Expect(err).To(Succeed())
-				config.Reset()
-				err = os.RemoveAll(dir)
+				config.CfgFile = configFile
+				configExitCode = 0
+				config.Init()
+				Expect(configExitCode).To(Equal(2))
+				Expect(config.GetSnapshot().HasPayloadMode(config.PayloadModeEnv)).To(BeFalse())
+				s, err := config.New(config.Config{MainScriptFolder: dir})
 				Expect(err).To(Succeed())
-			})
-			It("succeed for postscript", func() {
-				c := &config.Config{
-					PostScriptFolder: "/no_such_folder",
-				}
-				err := c.AddPostScript()
-				Expect(err).ToNot(Succeed())
-				Expect(err.Error()).To(ContainSubstring("no such file or directory"))
-			})
-			It("got nothing for postscript", func() {
-				c := &config.Config{}
-				err := c.AddPostScript()
+				Expect(s.GetPayloadModes()).To(Equal([]string{config.PayloadModeFile}))
+				err = os.RemoveAll(dir)
 				Expect(err).To(Succeed())
 			})
-			It("succeed for prescript", func() {
-				dir, err := os.MkdirTemp("", "pre_dir")
+			It("failed on hook", func() {
+				dir, err := os.MkdirTemp("", "process_dir")
 				Expect(err).To(Succeed())
 				_, err = os.CreateTemp(dir, "directory")
 				Expect(err).To(Succeed())
 				filename := dir + string(os.PathSeparator) + "script1.sh"
 				err = os.WriteFile(filename, []byte(fileTest), 0644)
 				Expect(err).To(Succeed())
-				c := &config.Config{
-					PreScriptFolder: dir,
-				}
-				err = c.AddPreScript()
+				configFile := dir + string(os.PathSeparator) + "config.yaml"
+				data := fmt.Sprintf(configTestFileWithHook, "main_script_folder", dir, "http://{}")
+				err = os.WriteFile(configFile, []byte(data), 0444)
 				Expect(err).To(Succeed())
-				config.Reset()
+				config.CfgFile = configFile
+				config.Init()
+				Expect(configExitCode).To(Equal(2))
 				err = os.RemoveAll(dir)
 				Expect(err).To(Succeed())
 			})
-			It("succeed for prescript", func() {
-				c := &config.Config{
-					PreScriptFolder: "/no_such_folder",
-				}
-				err := c.AddPreScript()
-				Expect(err).ToNot(Succeed())
-				Expect(err.Error()).To(ContainSubstring("no such file or directory"))
-			})
-			It("got nothing for postscript", func() {
-				c := &config.Config{}
-				err := c.AddPreScript()
+		})
+		Context("new", func() {
+			var dir string
+			folder := func(name string) string {
+				folder := dir + string(os.PathSeparator) + name
+				Expect(os.Mkdir(folder, 0755)).To(Succeed())
+				Expect(os.WriteFile(folder+string(os.PathSeparator)+"script1.sh", []byte(fileTest), 0644)).To(Succeed())
+				return folder
+			}
+			BeforeEach(func() {
+				var err error
+				dir, err = os.MkdirTemp("", "new_dir")
 				Expect(err).To(Succeed())
 			})
-			It("succeed for process script", func() {
-				dir, err := os.MkdirTemp("", "process_dir")
-				Expect(err).To(Succeed())
-				_, err = os.CreateTemp(dir, "directory")
-				Expect(err).To(Succeed())
-				filename := dir + string(os.PathSeparator) + "script1.sh"
-				err = os.WriteFile(filename, []byte(fileTest), 0644)
-				Expect(err).To(Succeed())
-				c := &config.Config{
-					MainScriptFolder: dir,
-				}
-				err = c.AddProcessScript()
-				Expect(err).To(Succeed())
-				config.Reset()
-				err = os.RemoveAll(dir)
-				Expect(err).To(Succeed())
+			AfterEach(func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
 			})
-			It("succeed for prescript", func() {
-				c := &config.Config{
-					MainScriptFolder: "/no_such_folder",
-				}
-				err := c.AddProcessScript()
-				Expect(err).ToNot(Succeed())
-				Expect(err.Error()).To(ContainSubstring("no such file or directory"))
+			It("loads the scripts of the folders", func() {
+				s, err := config.New(config.Config{
+					PreScriptFolder:     folder("pre"),
+					MainScriptFolder:    folder("main"),
+					PostScriptFolder:    folder("post"),
+					FinallyScriptFolder: folder("finally"),
+				})
+				Expect(err).To(Succeed())
+				Expect(s.GetPreScript()).To(Equal([]string{dir + "/pre/script1.sh"}))
+				Expect(s.GetMainScript()).To(Equal([]string{dir + "/main/script1.sh"}))
+				Expect(s.GetPostScript()).To(Equal([]string{dir + "/post/script1.sh"}))
+				Expect(s.GetFinallyScript()).To(Equal([]string{dir + "/finally/script1.sh"}))
 			})
-			It("got nothing for postscript", func() {
-				c := &config.Config{}
-				err := c.AddProcessScript()
-				Expect(err).To(Succeed())
+			It("fails on missing folder", func() {
+				main := folder("main")
+				for _, c := range []config.Config{
+					{PreScriptFolder: "/no_such_folder", MainScriptFolder: main},
+					{MainScriptFolder: "/no_such_folder"},
+					{PostScriptFolder: "/no_such_folder", MainScriptFolder: main},
+					{FinallyScriptFolder: "/no_such_folder", MainScriptFolder: main},
+				} {
+					_, err := config.New(c)
+					Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+				}
 			})
-			It("check add function with empty path", func() {
-				config.AddPreScript("")
-				config.AddMainScript("")
-				config.AddPostScript("")
+			It("requires a main script", func() {
+				_, err := config.New(config.Config{PostScriptFolder: folder("post")})
+				Expect(err).To(MatchError("a process script should be set"))
 			})
-			It("", func() {
-				scripts := config.GetPreScript()
-				Expect(len(scripts)).To(Equal(0))
-				scripts = config.GetMainScript()
-				Expect(len(scripts)).To(Equal(0))
-				scripts = config.GetPostScript()
-				Expect(len(scripts)).To(Equal(0))
+			It("does not change the active configuration", func() {
+				active := config.GetSnapshot()
+				_, err := config.New(config.Config{MainScriptFolder: folder("main")})
+				Expect(err).To(Succeed())
+				Expect(config.GetSnapshot()).To(BeIdenticalTo(active))
 			})
 		})
 	})
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/w6d-io/hook"
+	"github.com/w6d-io/x/logx"
+)
+
+// hookMu serializes the subscriptions of the hooks with the notifications
+var hookMu sync.RWMutex
+
+// Send notifies the hooks subscribed to the scope with the payload in the
+// background. A reload does not change the hooks until it is sent
+func Send(ctx context.Context, payload interface{}, scope string) {
+	hookMu.RLock()
+	go func() {
+		defer hookMu.RUnlock()
+		if err := hook.DoSend(ctx, payload, scope); err != nil {
+			logx.WithName(ctx, "Config.Send").Error(err, "send failed", "scope", scope)
+		}
+	}()
+}
+
+// activate subscribes the hooks of the snapshot in place of the active ones
+// then makes it the active snapshot. The active hooks are restored on failure
+func (s *Snapshot) activate() error {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	hook.CleanSubscriber()
+	if err := s.subscribe(); err != nil {
+		hook.CleanSubscriber()
+		_ = current().subscribe()
+		return err
+	}
+	s.Revision = revision.Add(1)
+	s.LoadedAt = time.Now()
+	store(s)
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the optional file of a script folder declaring the order
+// and the run options of its scripts
+const ManifestFile = "manifest.yaml"
+
+// Manifest declares the scripts of a folder in their run order
+type Manifest struct {
+	Groups  []Group  `json:"groups" yaml:"groups"`
+	Scripts []Script `json:"scripts" yaml:"scripts"`
+}
+
+// Group runs its consecutive scripts in parallel
+type Group struct {
+	Name string `json:"name" yaml:"name"`
+	// MaxParallel is the number of scripts run at the same time, all when 0
+	MaxParallel int `json:"max_parallel" yaml:"max_parallel"`
+	// FailFast stops the other scripts on the first failure, otherwise all
+	// the scripts of the group are waited for
+	FailFast bool `json:"fail_fast" yaml:"fail_fast"`
+}
+
+// Script holds the run options of a script
+type Script struct {
+	// Name is the file name of the script in the folder
+	Name string `json:"name" yaml:"name"`
+	// Path is the path of the script, set on load
+	Path string `json:"-" yaml:"-"`
+	// Timeout overrides the script timeout of the job
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Retries is the number of runs after a failure
+	Retries int `json:"retries" yaml:"retries"`
+	// Backoff is the delay before the first retry, doubled on each retry
+	Backoff time.Duration `json:"backoff" yaml:"backoff"`
+	// AllowFailure runs the next scripts when the script fails
+	AllowFailure bool `json:"allow_failure" yaml:"allow_failure"`
+	// Enabled skips the script when false
+	Enabled *bool `json:"enabled" yaml:"enabled"`
+	// Condition skips the script when not met by the payload
+	Condition string `json:"condition" yaml:"condition"`
+	// Group is the name of the parallel group of the script
+	Group string `json:"group" yaml:"group"`
+
+	// When is the parsed condition
+	When *Condition `json:"-" yaml:"-"`
+	// Parallel is the group of the script, set on load
+	Parallel *Group `json:"-" yaml:"-"`
+}
+
+// Condition is a test on the value at a path of the payload. Without
+// operator the value has to be set and neither false nor 0
+type Condition struct {
+	Not      bool
+	Path     string
+	Operator string
+	Value    string
+}
+
+var (
+	// ErrInvalidCondition is returned when the condition cannot be parsed
+	ErrInvalidCondition = errors.New("invalid condition")
+)
+
+// IsEnabled returns whether the script is enabled
+func (s Script) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// ParseCondition parses the expressions `$.path`, `!$.path`,
+// `$.path == value` and `$.path != value`. The value can be quoted
+func ParseCondition(expression string) (*Condition, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, nil
+	}
+	c := &Condition{}
+	for _, op := range []string{"==", "!="} {
+		if path, value, ok := strings.Cut(expression, op); ok {
+			c.Path, c.Operator, c.Value = strings.TrimSpace(path), op, strings.TrimSpace(value)
+			if len(c.Value) >= 2 && (c.Value[0] == '"' || c.Value[0] == '\'') && c.Value[len(c.Value)-1] == c.Value[0] {
+				c.Value = c.Value[1 : len(c.Value)-1]
+			}
+			break
+		}
+	}
+	if c.Operator == "" {
+		c.Path = expression
+		if strings.HasPrefix(expression, "!") {
+			c.Not, c.Path = true, strings.TrimSpace(expression[1:])
+		}
+	}
+	if !strings.HasPrefix(c.Path, "$.") || strings.ContainsAny(c.Path, " !=") {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCondition, expression)
+	}
+	return c, nil
+}
+
+// ReadManifest returns the manifest of the folder, nil when there is none
+func ReadManifest(folder string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(folder, ManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ManifestFile, err)
+	}
+	groups := make(map[string]*Group)
+	for i := range m.Groups {
+		g := &m.Groups[i]
+		if g.Name == "" || groups[g.Name] != nil || g.MaxParallel < 0 {
+			return nil, fmt.Errorf("invalid group %q in %s", g.Name, ManifestFile)
+		}
+		groups[g.Name] = g
+	}
+	done := make(map[string]bool)
+	for i := range m.Scripts {
+		s := &m.Scripts[i]
+		if s.Group != "" {
+			if s.Parallel = groups[s.Group]; s.Parallel == nil {
+				return nil, fmt.Errorf("unknown group %q of script %s in %s", s.Group, s.Name, ManifestFile)
+			}
+			if done[s.Group] {
+				return nil, fmt.Errorf("scripts of group %q are not consecutive in %s", s.Group, ManifestFile)
+			}
+		}
+		if i > 0 && m.Scripts[i-1].Group != s.Group {
+			done[m.Scripts[i-1].Group] = true
+		}
+		if s.Name == "" || s.Name != filepath.Base(s.Name) {
+			return nil, fmt.Errorf("invalid script name %q in %s", s.Name, ManifestFile)
+		}
+		if s.Retries < 0 || s.Timeout < 0 || s.Backoff < 0 {
+			return nil, fmt.Errorf("negative option of script %s in %s", s.Name, ManifestFile)
+		}
+		if s.When, err = ParseCondition(s.Condition); err != nil {
+			return nil, fmt.Errorf("script %s: %w", s.Name, err)
+		}
+	}
+	return m, nil
+}
+
+// scriptPaths returns the scripts of the folder and sets the run options of
+// the scripts of its manifest
+func (sn *Snapshot) scriptPaths(folder string) ([]string, error) {
+	files, err := os.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ReadManifest(folder)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	listed := make(map[string]bool)
+	if m != nil {
+		for _, s := range m.Scripts {
+			if listed[s.Name] {
+				return nil, fmt.Errorf("script %s listed twice in %s", s.Name, ManifestFile)
+			}
+			s.Path = fmt.Sprintf("%s%c%s", folder, os.PathSeparator, s.Name)
+			if info, err := os.Stat(s.Path); err != nil || info.IsDir() {
+				return nil, fmt.Errorf("script %s of %s not found", s.Name, ManifestFile)
+			}
+			listed[s.Name] = true
+			if err := sn.setScript(s); err != nil {
+				return nil, err
+			}
+			paths = append(paths, s.Path)
+		}
+	}
+	for _, file := range files {
+		if file.IsDir() || file.Name() == ManifestFile || listed[file.Name()] {
+			continue
+		}
+		paths = append(paths, fmt.Sprintf("%s%c%s", folder, os.PathSeparator, file.Name()))
+	}
+	return paths, nil
+}
+
+// GetScript returns the run options of the script
+func (sn *Snapshot) GetScript(path string) Script {
+	if s, ok := sn.scripts[path]; ok {
+		return s
+	}
+	return Script{Name: filepath.Base(path), Path: path}
+}
+
+// setScript sets the run options of the script at its path
+func (sn *Snapshot) setScript(s Script) error {
+	if s.Name == "" {
+		s.Name = filepath.Base(s.Path)
+	}
+	when, err := ParseCondition(s.Condition)
+	if err != nil {
+		return err
+	}
+	s.When = when
+	sn.scripts[s.Path] = s
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+var _ = Describe("Manifest", func() {
+	Context("ParseCondition", func() {
+		It("parses the expressions", func() {
+			c, err := config.ParseCondition("$.redis.enabled")
+			Expect(err).To(Succeed())
+			Expect(*c).To(Equal(config.Condition{Path: "$.redis.enabled"}))
+			c, err = config.ParseCondition("!$.redis.enabled")
+			Expect(err).To(Succeed())
+			Expect(*c).To(Equal(config.Condition{Not: true, Path: "$.redis.enabled"}))
+			c, err = config.ParseCondition(`$.env == "prod"`)
+			Expect(err).To(Succeed())
+			Expect(*c).To(Equal(config.Condition{Path: "$.env", Operator: "==", Value: "prod"}))
+			c, err = config.ParseCondition("$.images[0].tag != latest")
+			Expect(err).To(Succeed())
+			Expect(*c).To(Equal(config.Condition{Path: "$.images[0].tag", Operator: "!=", Value: "latest"}))
+			c, err = config.ParseCondition("")
+			Expect(err).To(Succeed())
+			Expect(c).To(BeNil())
+		})
+		It("fails on invalid expressions", func() {
+			for _, expression := range []string{"env == prod", "$.a b", "$.a >= 1"} {
+				_, err := config.ParseCondition(expression)
+				Expect(errors.Is(err, config.ErrInvalidCondition)).To(BeTrue(), expression)
+			}
+		})
+	})
+	Context("scripts", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "manifest")
+			Expect(err).To(Succeed())
+			for _, name := range []string{"a.sh", "b.sh", "c.sh"} {
+				Expect(os.WriteFile(filepath.Join(dir, name), []byte(fileTest), 0755)).To(Succeed())
+			}
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		load := func() (*config.Snapshot, error) {
+			return config.New(config.Config{MainScriptFolder: dir})
+		}
+		manifest := func(content string) {
+			Expect(os.WriteFile(filepath.Join(dir, config.ManifestFile), []byte(content), 0644)).To(Succeed())
+		}
+		It("returns the folder files without manifest", func() {
+			sn, err := load()
+			Expect(err).To(Succeed())
+			Expect(sn.GetMainScript()).To(Equal([]string{filepath.Join(dir, "a.sh"), filepath.Join(dir, "b.sh"), filepath.Join(dir, "c.sh")}))
+		})
+		It("orders the scripts of the manifest first", func() {
+			manifest(`scripts:
+  - name: c.sh
+    timeout: 1m
+    retries: 2
+    backoff: 1s
+    allow_failure: true
+  - name: a.sh
+    enabled: false
+    condition: $.redis.enabled
+`)
+			sn, err := load()
+			Expect(err).To(Succeed())
+			Expect(sn.GetMainScript()).To(Equal([]string{filepath.Join(dir, "c.sh"), filepath.Join(dir, "a.sh"), filepath.Join(dir, "b.sh")}))
+			s := sn.GetScript(filepath.Join(dir, "c.sh"))
+			Expect(s.Timeout).To(Equal(time.Minute))
+			Expect(s.Retries).To(Equal(2))
+			Expect(s.Backoff).To(Equal(time.Second))
+			Expect(s.AllowFailure).To(BeTrue())
+			Expect(s.IsEnabled()).To(BeTrue())
+			s = sn.GetScript(filepath.Join(dir, "a.sh"))
+			Expect(s.IsEnabled()).To(BeFalse())
+			Expect(s.When).To(Equal(&config.Condition{Path: "$.redis.enabled"}))
+			s = sn.GetScript(filepath.Join(dir, "b.sh"))
+			Expect(s.Name).To(Equal("b.sh"))
+			Expect(s.Retries).To(BeZero())
+		})
+		It("resolves the groups of the scripts", func() {
+			manifest(`groups:
+  - name: charts
+    max_parallel: 2
+    fail_fast: true
+scripts:
+  - name: a.sh
+    group: charts
+  - name: b.sh
+    group: charts
+`)
+			sn, err := load()
+			Expect(err).To(Succeed())
+			a := sn.GetScript(filepath.Join(dir, "a.sh"))
+			b := sn.GetScript(filepath.Join(dir, "b.sh"))
+			Expect(a.Parallel).To(Equal(&config.Group{Name: "charts", MaxParallel: 2, FailFast: true}))
+			Expect(a.Parallel).To(BeIdenticalTo(b.Parallel))
+			Expect(sn.GetScript(filepath.Join(dir, "c.sh")).Parallel).To(BeNil())
+		})
+		It("fails on invalid groups", func() {
+			manifest("scripts:\n  - name: a.sh\n    group: charts\n")
+			_, err := load()
+			Expect(err).To(HaveOccurred())
+			manifest("groups:\n  - name: charts\n  - name: charts\n")
+			_, err = load()
+			Expect(err).To(HaveOccurred())
+			manifest(`groups:
+  - name: charts
+scripts:
+  - name: a.sh
+    group: charts
+  - name: b.sh
+  - name: c.sh
+    group: charts
+`)
+			_, err = load()
+			Expect(err).To(HaveOccurred())
+		})
+		It("fails on unknown script", func() {
+			manifest("scripts:\n  - name: d.sh\n")
+			_, err := load()
+			Expect(err).To(HaveOccurred())
+		})
+		It("fails on script listed twice", func() {
+			manifest("scripts:\n  - name: a.sh\n  - name: a.sh\n")
+			_, err := load()
+			Expect(err).To(HaveOccurred())
+		})
+		It("fails on invalid options", func() {
+			manifest("scripts:\n  - name: ../a.sh\n")
+			_, err := load()
+			Expect(err).To(HaveOccurred())
+			manifest("scripts:\n  - name: a.sh\n    retries: -1\n")
+			_, err = load()
+			Expect(err).To(HaveOccurred())
+			manifest("scripts:\n  - name: a.sh\n    condition: redis\n")
+			_, err = load()
+			Expect(err).To(HaveOccurred())
+			manifest("scripts: [")
+			_, err = load()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the
+// configuration file, followed by the upper-cased yaml key
+const EnvPrefix = "PROCESS_REST_"
+
+var (
+	// variable matches the ${VAR} references expanded in the configuration file
+	variable = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)}`)
+
+	// flagSet holds the command-line flags bound by BindFlags
+	flagSet *pflag.FlagSet
+	// flagConfig receives the values of the command-line flags
+	flagConfig Config
+	// flagHooks receives the values of the hook flag
+	flagHooks []string
+)
+
+// Expand replaces the ${VAR} references by the value of the environment
+// variables, empty when unset
+func Expand(data []byte) []byte {
+	return variable.ReplaceAllFunc(data, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(variable.FindSubmatch(ref)[1])))
+	})
+}
+
+// BindFlags adds the flags overriding the configuration to the flag set
+func BindFlags(fs *pflag.FlagSet) {
+	flagSet = fs
+	fs.StringVar(&flagConfig.Listen, "listen", "", "address the server listens on, "+DefaultListen+" by default")
+	fs.StringVar(&flagConfig.PreScriptFolder, "pre-script-folder", "", "folder of the pre scripts")
+	fs.StringVar(&flagConfig.MainScriptFolder, "main-script-folder", "", "folder of the main scripts")
+	fs.StringVar(&flagConfig.PostScriptFolder, "post-script-folder", "", "folder of the post scripts")
+	fs.StringVar(&flagConfig.FinallyScriptFolder, "finally-script-folder", "", "folder of the finally scripts")
+	fs.StringArrayVar(&flagHooks, "hook", nil, "hook URL or {url: ..., scope: ...} mapping, repeatable")
+	fs.DurationVar(&flagConfig.Timeout, "timeout", 0, "maximum duration of a whole job")
+	fs.DurationVar(&flagConfig.ScriptTimeout, "script-timeout", 0, "maximum duration of each script")
+	fs.IntVar(&flagConfig.Concurrency, "concurrency", 0, "number of jobs run at the same time")
+	fs.IntVar(&flagConfig.QueueSize, "queue-size", 0, "number of jobs waiting for a free slot")
+}
+
+// Override applies the environment variables then the command-line flags
+// on the configuration read from the file
+func Override(c *Config) error {
+	if err := overrideEnv(c); err != nil {
+		return err
+	}
+	return overrideFlags(c)
+}
+
+// read returns the configuration of the file, with its ${VAR} references
+// expanded, overridden by the environment and the flags. The file is
+// optional when the filename is empty
+func read(filename string) (*Config, error) {
+	c := new(Config)
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(Expand(data), c); err != nil {
+			return nil, fmt.Errorf("unmarshal the configuration: %w", err)
+		}
+	}
+	if err := Override(c); err != nil {
+		return nil, fmt.Errorf("override the configuration: %w", err)
+	}
+	return c, nil
+}
+
+// overrideEnv sets the fields having a PROCESS_REST_<KEY> environment
+// variable. Strings are taken as is, string lists are comma separated and
+// the other values are parsed as YAML
+func overrideEnv(c *Config) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		name := EnvPrefix + strings.ToUpper(key)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.String:
+			field.SetString(value)
+		case field.Type() == reflect.TypeOf([]string(nil)):
+			var list []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			field.Set(reflect.ValueOf(list))
+		default:
+			if err := yaml.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
+				return fmt.Errorf("parse %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// overrideFlags sets the fields of the flags given on the command line
+func overrideFlags(c *Config) error {
+	if flagSet == nil {
+		return nil
+	}
+	for name, set := range map[string]func(){
+		"listen":                func() { c.Listen = flagConfig.Listen },
+		"pre-script-folder":     func() { c.PreScriptFolder = flagConfig.PreScriptFolder },
+		"main-script-folder":    func() { c.MainScriptFolder = flagConfig.MainScriptFolder },
+		"post-script-folder":    func() { c.PostScriptFolder = flagConfig.PostScriptFolder },
+		"finally-script-folder": func() { c.FinallyScriptFolder = flagConfig.FinallyScriptFolder },
+		"timeout":               func() { c.Timeout = flagConfig.Timeout },
+		"script-timeout":        func() { c.ScriptTimeout = flagConfig.ScriptTimeout },
+		"concurrency":           func() { c.Concurrency = flagConfig.Concurrency },
+		"queue-size":            func() { c.QueueSize = flagConfig.QueueSize },
+	} {
+		if flagSet.Changed(name) {
+			set()
+		}
+	}
+	if !flagSet.Changed("hook") {
+		return nil
+	}
+	hooks := make([]Hook, 0, len(flagHooks))
+	for _, value := range flagHooks {
+		h, err := parseHook(value)
+		if err != nil {
+			return err
+		}
+		hooks = append(hooks, h)
+	}
+	c.Hooks = hooks
+	return nil
+}
+
+// parseHook returns the hook of a plain URL or of a YAML mapping
+func parseHook(value string) (Hook, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return Hook{URL: value}, nil
+	}
+	var h Hook
+	if err := yaml.Unmarshal([]byte(value), &h); err != nil {
+		return Hook{}, fmt.Errorf("parse hook %q: %w", value, err)
+	}
+	if h.URL == "" {
+		return Hook{}, fmt.Errorf("parse hook %q: url is missing", value)
+	}
+	return h, nil
+}
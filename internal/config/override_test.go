@@ -0,0 +1,127 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+var _ = Describe("Override", func() {
+	var (
+		dir  string
+		envs []string
+	)
+	setenv := func(name, value string) {
+		Expect(os.Setenv(name, value)).To(Succeed())
+		envs = append(envs, name)
+	}
+	bind := func(args ...string) {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		config.BindFlags(fs)
+		Expect(fs.Parse(args)).To(Succeed())
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "override")
+		Expect(err).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "main.sh"), []byte("#!/bin/bash\n"), 0755)).To(Succeed())
+		envs = nil
+	})
+	AfterEach(func() {
+		for _, name := range envs {
+			Expect(os.Unsetenv(name)).To(Succeed())
+		}
+		bind()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("expands the ${VAR} references", func() {
+		setenv("OVERRIDE_FOLDER", "/scripts")
+		data := config.Expand([]byte("main_script_folder: ${OVERRIDE_FOLDER}/main\nlock_key_path: $.name\nschema: ${OVERRIDE_UNSET}"))
+		Expect(string(data)).To(Equal("main_script_folder: /scripts/main\nlock_key_path: $.name\nschema: "))
+	})
+	It("overrides the configuration with the environment", func() {
+		setenv("PROCESS_REST_LISTEN", ":9090")
+		setenv("PROCESS_REST_TIMEOUT", "1m")
+		setenv("PROCESS_REST_CONCURRENCY", "2")
+		setenv("PROCESS_REST_PAYLOAD_MODES", "file, env")
+		setenv("PROCESS_REST_HOOK_LEGACY_LOG", "true")
+		setenv("PROCESS_REST_HOOKS", `[{url: "http://localhost:8888", scope: "end"}]`)
+		c := config.Config{Listen: ":8000", Concurrency: 8}
+		Expect(config.Override(&c)).To(Succeed())
+		Expect(c.Listen).To(Equal(":9090"))
+		Expect(c.Timeout).To(Equal(time.Minute))
+		Expect(c.Concurrency).To(Equal(2))
+		Expect(c.PayloadModes).To(Equal([]string{"file", "env"}))
+		Expect(c.HookLegacyLog).To(BeTrue())
+		Expect(c.Hooks).To(Equal([]config.Hook{{URL: "http://localhost:8888", Scope: "end"}}))
+	})
+	It("fails on an invalid environment value", func() {
+		setenv("PROCESS_REST_CONCURRENCY", "many")
+		Expect(config.Override(&config.Config{})).To(MatchError(ContainSubstring("PROCESS_REST_CONCURRENCY")))
+	})
+	It("overrides the environment with the flags", func() {
+		setenv("PROCESS_REST_LISTEN", ":9090")
+		setenv("PROCESS_REST_QUEUE_SIZE", "5")
+		bind("--listen", ":7070", "--script-timeout", "30s",
+			"--hook", "http://localhost:8888", "--hook", `{url: "http://localhost:9999", scope: "start"}`)
+		c := config.Config{Concurrency: 8}
+		Expect(config.Override(&c)).To(Succeed())
+		Expect(c.Listen).To(Equal(":7070"))
+		Expect(c.QueueSize).To(Equal(5))
+		Expect(c.Concurrency).To(Equal(8))
+		Expect(c.ScriptTimeout).To(Equal(30 * time.Second))
+		Expect(c.Hooks).To(Equal([]config.Hook{
+			{URL: "http://localhost:8888"},
+			{URL: "http://localhost:9999", Scope: "start"},
+		}))
+	})
+	It("fails on a hook without url", func() {
+		bind("--hook", `{scope: "start"}`)
+		Expect(config.Override(&config.Config{})).To(MatchError(ContainSubstring("url is missing")))
+	})
+	It("layers the file, the environment and the flags", func() {
+		setenv("OVERRIDE_DIR", dir)
+		setenv("PROCESS_REST_CONCURRENCY", "3")
+		Expect(os.Mkdir(filepath.Join(dir, "config"), 0755)).To(Succeed())
+		filename := filepath.Join(dir, "config", "config.yaml")
+		Expect(os.WriteFile(filename, []byte("main_script_folder: ${OVERRIDE_DIR}\nconcurrency: 1\nqueue_size: 10\n"), 0644)).To(Succeed())
+		bind("--queue-size", "20")
+		s, err := config.Load(filename)
+		Expect(err).To(Succeed())
+		Expect(s.GetMainScript()).To(HaveLen(1))
+		Expect(s.GetConcurrency()).To(Equal(3))
+		Expect(s.GetQueueSize()).To(Equal(20))
+		Expect(s.GetListen()).To(Equal(config.DefaultListen))
+	})
+	It("loads without configuration file", func() {
+		setenv("PROCESS_REST_MAIN_SCRIPT_FOLDER", dir)
+		bind("--listen", "127.0.0.1:8081")
+		s, err := config.Load("")
+		Expect(err).To(Succeed())
+		Expect(s.GetMainScript()).To(HaveLen(1))
+		Expect(s.GetListen()).To(Equal("127.0.0.1:8081"))
+	})
+})
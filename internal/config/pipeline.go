@@ -0,0 +1,260 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/w6d-io/hook"
+)
+
+// Pipeline is a named set of scripts reachable on POST /process/:pipeline.
+// The unset options fall back to the top-level ones
+type Pipeline struct {
+	Name                string `json:"name" yaml:"name"`
+	PreScriptFolder     string `json:"pre_script_folder" yaml:"pre_script_folder"`
+	MainScriptFolder    string `json:"main_script_folder" yaml:"main_script_folder"`
+	PostScriptFolder    string `json:"post_script_folder" yaml:"post_script_folder"`
+	FinallyScriptFolder string `json:"finally_script_folder" yaml:"finally_script_folder"`
+	Steps               []Step `json:"steps" yaml:"steps"`
+	// Hooks receive the notifications of the pipeline only
+	Hooks []Hook `json:"hooks" yaml:"hooks"`
+
+	Timeout       time.Duration `json:"timeout" yaml:"timeout"`
+	ScriptTimeout time.Duration `json:"script_timeout" yaml:"script_timeout"`
+	// Concurrency is the number of jobs of the pipeline run at the same
+	// time, only bounded by the global concurrency when 0
+	Concurrency    int      `json:"concurrency" yaml:"concurrency"`
+	LockKeyPath    string   `json:"lock_key_path" yaml:"lock_key_path"`
+	CleanupScripts []string `json:"cleanup_scripts" yaml:"cleanup_scripts"`
+	PayloadModes   []string `json:"payload_modes" yaml:"payload_modes"`
+	// Schema is the JSON Schema file the payloads are validated against
+	Schema string `json:"schema" yaml:"schema"`
+
+	snapshot      *Snapshot
+	schema        *jsonschema.Schema
+	preScript     []string
+	mainScript    []string
+	postScript    []string
+	finallyScript []string
+}
+
+var pipelineName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// GetPipeline returns the pipeline of the name, the default one built from the
+// top-level options when the name is empty
+func (s *Snapshot) GetPipeline(name string) (*Pipeline, bool) {
+	if name == "" {
+		c := s.config
+		return &Pipeline{
+			Steps:          c.Steps,
+			Timeout:        c.Timeout,
+			ScriptTimeout:  c.ScriptTimeout,
+			LockKeyPath:    c.LockKeyPath,
+			CleanupScripts: c.CleanupScripts,
+			PayloadModes:   c.PayloadModes,
+			Schema:         c.Schema,
+			snapshot:       s,
+			schema:         s.schema,
+			preScript:      s.preScript,
+			mainScript:     s.mainScript,
+			postScript:     s.postScript,
+			finallyScript:  s.finallyScript,
+		}, true
+	}
+	p, ok := s.pipelines[name]
+	return p, ok
+}
+
+// addPipeline loads the scripts of the pipeline into the snapshot
+func (s *Snapshot) addPipeline(p Pipeline) error {
+	if !pipelineName.MatchString(p.Name) {
+		return fmt.Errorf("invalid pipeline name %q", p.Name)
+	}
+	if _, ok := s.pipelines[p.Name]; ok {
+		return fmt.Errorf("pipeline %s declared twice", p.Name)
+	}
+	for _, folder := range []struct {
+		path    string
+		scripts *[]string
+	}{
+		{p.PreScriptFolder, &p.preScript},
+		{p.MainScriptFolder, &p.mainScript},
+		{p.PostScriptFolder, &p.postScript},
+		{p.FinallyScriptFolder, &p.finallyScript},
+	} {
+		if folder.path == "" {
+			continue
+		}
+		paths, err := s.scriptPaths(folder.path)
+		if err != nil {
+			return fmt.Errorf("pipeline %s: %w", p.Name, err)
+		}
+		*folder.scripts = paths
+	}
+	if err := ValidateSteps(p.Steps); err != nil {
+		return fmt.Errorf("pipeline %s: %w", p.Name, err)
+	}
+	if len(p.mainScript) == 0 && len(p.Steps) == 0 {
+		return fmt.Errorf("pipeline %s: a process script should be set", p.Name)
+	}
+	if err := checkPayloadModes(p.PayloadModes); err != nil {
+		return fmt.Errorf("pipeline %s: %w", p.Name, err)
+	}
+	schema, err := LoadSchema(p.Schema)
+	if err != nil {
+		return fmt.Errorf("pipeline %s: %w", p.Name, err)
+	}
+	p.schema = schema
+	p.snapshot = s
+	s.pipelines[p.Name] = &p
+	return nil
+}
+
+// subscribe subscribes the hooks of the pipeline
+func (p *Pipeline) subscribe() error {
+	for _, h := range p.Hooks {
+		if err := hook.Subscribe(context.Background(), h.URL, p.HookScope(h.Scope)); err != nil {
+			return fmt.Errorf("pipeline %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Scope returns the notification scope of the pipeline, prefixed with the
+// name of a named pipeline
+func (p *Pipeline) Scope(scope string) string {
+	if p.Name == "" {
+		return scope
+	}
+	return p.Name + "/" + scope
+}
+
+// HookScope returns the subscription scope matching the notifications of
+// the pipeline only
+func (p *Pipeline) HookScope(scope string) string {
+	if scope == "*" {
+		scope = ".*"
+	}
+	return "^" + regexp.QuoteMeta(p.Name) + "/(?:" + scope + ")"
+}
+
+// GetPreScript returns the pre scripts of the pipeline
+func (p *Pipeline) GetPreScript() []string {
+	return p.preScript
+}
+
+// GetMainScript returns the main scripts of the pipeline
+func (p *Pipeline) GetMainScript() []string {
+	return p.mainScript
+}
+
+// GetPostScript returns the post scripts of the pipeline
+func (p *Pipeline) GetPostScript() []string {
+	return p.postScript
+}
+
+// GetFinallyScript returns the finally scripts of the pipeline
+func (p *Pipeline) GetFinallyScript() []string {
+	return p.finallyScript
+}
+
+// GetSteps returns the steps of the pipeline replacing the main scripts
+func (p *Pipeline) GetSteps() []Step {
+	return p.Steps
+}
+
+// GetTimeout returns the job timeout of the pipeline
+func (p *Pipeline) GetTimeout() time.Duration {
+	if p.Timeout == 0 {
+		return p.snapshot.config.Timeout
+	}
+	return p.Timeout
+}
+
+// GetScriptTimeout returns the script timeout of the pipeline
+func (p *Pipeline) GetScriptTimeout() time.Duration {
+	if p.ScriptTimeout == 0 {
+		return p.snapshot.config.ScriptTimeout
+	}
+	return p.ScriptTimeout
+}
+
+// GetLockKeyPath returns the path into the payload of the lock key
+func (p *Pipeline) GetLockKeyPath() string {
+	if p.LockKeyPath == "" {
+		return p.snapshot.config.LockKeyPath
+	}
+	return p.LockKeyPath
+}
+
+// HasPayloadMode returns whether the payload is delivered with the mode
+func (p *Pipeline) HasPayloadMode(mode string) bool {
+	if len(p.PayloadModes) == 0 {
+		return p.snapshot.HasPayloadMode(mode)
+	}
+	for _, m := range p.PayloadModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSchema returns the compiled JSON Schema of the payloads, nil when the
+// payloads are not validated
+func (p *Pipeline) GetSchema() *jsonschema.Schema {
+	if p.schema == nil {
+		return p.snapshot.schema
+	}
+	return p.schema
+}
+
+// GetScript returns the run options of the script in the snapshot of the
+// pipeline
+func (p *Pipeline) GetScript(path string) Script {
+	return p.snapshot.GetScript(path)
+}
+
+// GetSnapshot returns the snapshot the pipeline belongs to
+func (p *Pipeline) GetSnapshot() *Snapshot {
+	return p.snapshot
+}
+
+// GetCleanupScript returns the post scripts flagged as cleanup
+func (p *Pipeline) GetCleanupScript() []string {
+	names := p.CleanupScripts
+	if len(names) == 0 {
+		names = p.snapshot.config.CleanupScripts
+	}
+	var scripts []string
+	for _, script := range p.postScript {
+		for _, name := range names {
+			if filepath.Base(script) == name {
+				scripts = append(scripts, script)
+				break
+			}
+		}
+	}
+	return scripts
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+var _ = Describe("Pipeline", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "pipeline")
+		Expect(err).To(Succeed())
+		for _, folder := range []string{"pre", "main", "post"} {
+			Expect(os.Mkdir(filepath.Join(dir, folder), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, folder, "script.sh"), []byte(fileTest), 0755)).To(Succeed())
+		}
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("loads the scripts of a named pipeline", func() {
+		s, err := config.New(config.Config{
+			MainScriptFolder: filepath.Join(dir, "main"),
+			Pipelines: []config.Pipeline{{
+				Name:             "deploy",
+				PreScriptFolder:  filepath.Join(dir, "pre"),
+				MainScriptFolder: filepath.Join(dir, "main"),
+				Concurrency:      2,
+				Timeout:          time.Minute,
+			}},
+		})
+		Expect(err).To(Succeed())
+		p, ok := s.GetPipeline("deploy")
+		Expect(ok).To(BeTrue())
+		Expect(p.GetPreScript()).To(Equal([]string{filepath.Join(dir, "pre", "script.sh")}))
+		Expect(p.GetMainScript()).To(Equal([]string{filepath.Join(dir, "main", "script.sh")}))
+		Expect(p.GetPostScript()).To(BeEmpty())
+		Expect(p.GetTimeout()).To(Equal(time.Minute))
+		Expect(p.Concurrency).To(Equal(2))
+		Expect(p.GetSnapshot()).To(BeIdenticalTo(s))
+		_, ok = s.GetPipeline("unknown")
+		Expect(ok).To(BeFalse())
+	})
+	It("falls back to the top-level options", func() {
+		s, err := config.New(config.Config{
+			MainScriptFolder: filepath.Join(dir, "main"),
+			LockKeyPath:      "$.name",
+			Pipelines: []config.Pipeline{{
+				Name:             "build",
+				MainScriptFolder: filepath.Join(dir, "main"),
+			}},
+		})
+		Expect(err).To(Succeed())
+		p, ok := s.GetPipeline("")
+		Expect(ok).To(BeTrue())
+		Expect(p.GetMainScript()).To(HaveLen(1))
+		Expect(p.Scope("process-failed")).To(Equal("process-failed"))
+		p, _ = s.GetPipeline("build")
+		Expect(p.GetLockKeyPath()).To(Equal("$.name"))
+		Expect(p.HasPayloadMode(config.PayloadModeFile)).To(BeTrue())
+		Expect(p.Scope("process-failed")).To(Equal("build/process-failed"))
+	})
+	It("scopes the hooks of the pipeline", func() {
+		p := config.Pipeline{Name: "build"}
+		Expect(p.HookScope("*")).To(Equal("^build/(?:.*)"))
+		Expect(p.HookScope("main-process-failed")).To(Equal("^build/(?:main-process-failed)"))
+	})
+	It("fails on invalid pipelines", func() {
+		main := filepath.Join(dir, "main")
+		for _, pipelines := range [][]config.Pipeline{
+			{{Name: "a/b", MainScriptFolder: main}},
+			{{Name: "empty"}},
+			{{Name: "missing", MainScriptFolder: filepath.Join(dir, "missing")}},
+			{{Name: "mode", MainScriptFolder: main, PayloadModes: []string{"xml"}}},
+			{{Name: "twice", MainScriptFolder: main}, {Name: "twice", MainScriptFolder: main}},
+		} {
+			_, err := config.New(config.Config{MainScriptFolder: main, Pipelines: pipelines})
+			Expect(err).To(HaveOccurred(), pipelines[0].Name)
+		}
+	})
+})
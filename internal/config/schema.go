@@ -0,0 +1,36 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// LoadSchema compiles the JSON Schema file, nil without file
+func LoadSchema(filename string) (*jsonschema.Schema, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	s, err := jsonschema.Compile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("compile schema %s: %w", filename, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+var _ = Describe("Schema", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "schema")
+		Expect(err).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "main"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "main", "script.sh"), []byte(fileTest), 0755)).To(Succeed())
+	})
+	load := func(schema string) (*config.Snapshot, error) {
+		return config.New(config.Config{MainScriptFolder: filepath.Join(dir, "main"), Schema: schema})
+	}
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("does not validate without schema", func() {
+		s, err := config.LoadSchema("")
+		Expect(err).To(Succeed())
+		Expect(s).To(BeNil())
+		sn, err := load("")
+		Expect(err).To(Succeed())
+		p, _ := sn.GetPipeline("")
+		Expect(p.GetSchema()).To(BeNil())
+	})
+	It("compiles the schema of the pipelines", func() {
+		filename := filepath.Join(dir, "schema.json")
+		Expect(os.WriteFile(filename, []byte(`{"type": "object", "required": ["name"]}`), 0644)).To(Succeed())
+		sn, err := load(filename)
+		Expect(err).To(Succeed())
+		p, _ := sn.GetPipeline("")
+		Expect(p.GetSchema()).ToNot(BeNil())
+		Expect(p.GetSchema().Validate(map[string]interface{}{"name": "x"})).To(Succeed())
+		Expect(p.GetSchema().Validate(map[string]interface{}{})).ToNot(Succeed())
+	})
+	It("fails on invalid schema", func() {
+		filename := filepath.Join(dir, "schema.json")
+		Expect(os.WriteFile(filename, []byte(`{"type": 1}`), 0644)).To(Succeed())
+		_, err := load(filename)
+		Expect(err).To(HaveOccurred())
+		_, err = load(filepath.Join(dir, "missing.json"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,196 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/w6d-io/hook"
+
+	"github.com/w6d-io/x/logx"
+)
+
+// Snapshot is the configuration along with the scripts of its folders. A
+// snapshot is never modified once active, a reload swaps in a new one so the
+// running jobs keep the scripts they started with
+type Snapshot struct {
+	// Revision is incremented on each load of the configuration file
+	Revision int64
+	// LoadedAt is when the configuration file was loaded
+	LoadedAt time.Time
+
+	config        *Config
+	preScript     []string
+	mainScript    []string
+	postScript    []string
+	finallyScript []string
+	scripts       map[string]Script
+	pipelines     map[string]*Pipeline
+	schema        *jsonschema.Schema
+}
+
+// Source returns the snapshot the jobs are created from
+type Source func() *Snapshot
+
+var (
+	snapshot atomic.Pointer[Snapshot]
+	revision atomic.Int64
+)
+
+func init() {
+	snapshot.Store(newSnapshot(new(Config)))
+}
+
+// newSnapshot returns an empty snapshot of the configuration
+func newSnapshot(c *Config) *Snapshot {
+	return &Snapshot{
+		config:    c,
+		scripts:   make(map[string]Script),
+		pipelines: make(map[string]*Pipeline),
+	}
+}
+
+// current returns the active snapshot
+func current() *Snapshot {
+	return snapshot.Load()
+}
+
+// GetSnapshot returns the active snapshot. It is the Source following the
+// reloads
+func GetSnapshot() *Snapshot {
+	return current()
+}
+
+// Static returns the Source of the snapshot whatever the reloads
+func Static(s *Snapshot) Source {
+	return func() *Snapshot { return s }
+}
+
+// New returns the snapshot of the configuration value with the scripts of its
+// folders. It does not change the active snapshot
+func New(c Config) (*Snapshot, error) {
+	s := newSnapshot(&c)
+	if err := s.loadFolders(); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// store makes the snapshot the active one
+func store(s *Snapshot) {
+	snapshot.Store(s)
+}
+
+// Load reads the configuration file overridden by the environment and the
+// flags, and the scripts of its folders into a new snapshot
+func Load(filename string) (*Snapshot, error) {
+	c, err := read(filename)
+	if err != nil {
+		return nil, err
+	}
+	return New(*c)
+}
+
+// Reload loads the configuration file and swaps in the new snapshot with
+// its hooks. The active snapshot is kept on failure
+func Reload() error {
+	log := logx.WithName(nil, "Config.Reload")
+	s, err := Load(CfgFile)
+	if err != nil {
+		log.Error(err, "reload failed, keep the active configuration", "revision", current().Revision)
+		return err
+	}
+	if err := s.activate(); err != nil {
+		log.Error(err, "hook subscription failed")
+		return err
+	}
+	log.Info("configuration reloaded", "revision", s.Revision)
+	return nil
+}
+
+// loadFolders reads the scripts of the top-level folders
+func (s *Snapshot) loadFolders() error {
+	for _, folder := range []struct {
+		path    string
+		scripts *[]string
+	}{
+		{s.config.PreScriptFolder, &s.preScript},
+		{s.config.MainScriptFolder, &s.mainScript},
+		{s.config.PostScriptFolder, &s.postScript},
+		{s.config.FinallyScriptFolder, &s.finallyScript},
+	} {
+		if folder.path == "" {
+			continue
+		}
+		paths, err := s.scriptPaths(folder.path)
+		if err != nil {
+			return fmt.Errorf("get file in folder %s failed: %w", folder.path, err)
+		}
+		*folder.scripts = paths
+	}
+	return nil
+}
+
+// validate checks the options of the snapshot and loads its pipelines
+func (s *Snapshot) validate() error {
+	var err error
+	if err = checkPayloadModes(s.config.PayloadModes); err != nil {
+		return err
+	}
+	if _, err = GetInterpreterCommand(s.config.Interpreter); err != nil {
+		return err
+	}
+	if err = ValidateSteps(s.config.Steps); err != nil {
+		return err
+	}
+	if s.schema, err = LoadSchema(s.config.Schema); err != nil {
+		return err
+	}
+	if len(s.mainScript) == 0 && len(s.config.Steps) == 0 {
+		return errors.New("a process script should be set")
+	}
+	for _, p := range s.config.Pipelines {
+		if err := s.addPipeline(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscribe subscribes the hooks of the snapshot and of its pipelines
+func (s *Snapshot) subscribe() error {
+	for _, wh := range s.config.Hooks {
+		if err := hook.Subscribe(context.Background(), wh.URL, wh.Scope); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.pipelines {
+		if err := p.subscribe(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
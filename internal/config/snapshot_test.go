@@ -0,0 +1,132 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+var _ = Describe("Snapshot", func() {
+	var (
+		dir        string
+		configFile string
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "snapshot")
+		Expect(err).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "main"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "main", "a.sh"), []byte(fileTest), 0755)).To(Succeed())
+		configFile = filepath.Join(dir, "config.yaml")
+		Expect(os.WriteFile(configFile, []byte("main_script_folder: "+filepath.Join(dir, "main")+"\n"), 0644)).To(Succeed())
+		config.CfgFile = configFile
+	})
+	AfterEach(func() {
+		config.CfgFile = ""
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("swaps in a new revision and keeps the former snapshot intact", func() {
+		Expect(config.Reload()).To(Succeed())
+		revision := config.GetSnapshot().Revision
+		former, _ := config.GetSnapshot().GetPipeline("")
+		Expect(former.GetMainScript()).To(HaveLen(1))
+
+		Expect(os.WriteFile(filepath.Join(dir, "main", "b.sh"), []byte(fileTest), 0755)).To(Succeed())
+		Expect(config.Reload()).To(Succeed())
+		Expect(config.GetSnapshot().Revision).To(Equal(revision + 1))
+		Expect(config.GetSnapshot().GetMainScript()).To(HaveLen(2))
+		Expect(former.GetMainScript()).To(HaveLen(1))
+		Expect(former.GetSnapshot().Revision).To(Equal(revision))
+	})
+	It("keeps the active snapshot on invalid configuration", func() {
+		Expect(config.Reload()).To(Succeed())
+		revision := config.GetSnapshot().Revision
+		Expect(os.WriteFile(configFile, []byte("main_script_folder: "+filepath.Join(dir, "missing")+"\n"), 0644)).To(Succeed())
+		Expect(config.Reload()).ToNot(Succeed())
+		Expect(config.GetSnapshot().Revision).To(Equal(revision))
+		Expect(config.GetSnapshot().GetMainScript()).To(HaveLen(1))
+	})
+	It("swaps the hooks once the notifications in flight are sent", func() {
+		received := make(chan string, 2)
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received <- r.URL.Path
+			<-release
+		}))
+		defer server.Close()
+		defer func() {
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+		}()
+		hooks := func(path string) {
+			content := "main_script_folder: " + filepath.Join(dir, "main") + "\n"
+			if path != "" {
+				content += "hooks:\n  - url: " + server.URL + path + "\n    scope: \"*\"\n"
+			}
+			Expect(os.WriteFile(configFile, []byte(content), 0644)).To(Succeed())
+		}
+		defer func() {
+			hooks("")
+			Expect(config.Reload()).To(Succeed())
+		}()
+		hooks("/first")
+		Expect(config.Reload()).To(Succeed())
+		config.Send(context.Background(), "payload", "process-succeeded")
+		Eventually(received).Should(Receive(Equal("/first")))
+
+		hooks("/second")
+		reloaded := make(chan error)
+		go func() { reloaded <- config.Reload() }()
+		Consistently(reloaded, 200*time.Millisecond).ShouldNot(Receive())
+		close(release)
+		Eventually(reloaded).Should(Receive(BeNil()))
+		config.Send(context.Background(), "payload", "process-succeeded")
+		Eventually(received).Should(Receive(Equal("/second")))
+	})
+	It("reloads on the changes of the script folders", func() {
+		Expect(config.Reload()).To(Succeed())
+		revision := config.GetSnapshot().Revision
+		delay := config.ReloadDelay
+		config.ReloadDelay = 10 * time.Millisecond
+		defer func() { config.ReloadDelay = delay }()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- config.Watch(ctx) }()
+		getRevision := func() int64 { return config.GetSnapshot().Revision }
+		getMainScript := func() []string { return config.GetSnapshot().GetMainScript() }
+		Consistently(getRevision, 100*time.Millisecond).Should(Equal(revision))
+		Expect(os.WriteFile(filepath.Join(dir, "main", "b.sh"), []byte(fileTest), 0755)).To(Succeed())
+		Eventually(getMainScript, 2*time.Second).Should(HaveLen(2))
+		Expect(config.GetSnapshot().Revision).To(BeNumerically(">", revision))
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
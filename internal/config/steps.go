@@ -0,0 +1,121 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Step is a script of a pipeline run once the steps it needs succeeded
+type Step struct {
+	Name string `json:"name" yaml:"name"`
+	// Run is the path of the script
+	Run string `json:"run" yaml:"run"`
+	// Needs are the names of the steps to succeed before
+	Needs []string `json:"needs" yaml:"needs"`
+
+	Timeout      time.Duration `json:"timeout" yaml:"timeout"`
+	Retries      int           `json:"retries" yaml:"retries"`
+	Backoff      time.Duration `json:"backoff" yaml:"backoff"`
+	AllowFailure bool          `json:"allow_failure" yaml:"allow_failure"`
+	Condition    string        `json:"condition" yaml:"condition"`
+}
+
+// GetScript returns the script of the step with its run options
+func (s Step) GetScript() (Script, error) {
+	when, err := ParseCondition(s.Condition)
+	if err != nil {
+		return Script{}, err
+	}
+	return Script{
+		Name:         filepath.Base(s.Run),
+		Path:         s.Run,
+		Timeout:      s.Timeout,
+		Retries:      s.Retries,
+		Backoff:      s.Backoff,
+		AllowFailure: s.AllowFailure,
+		Condition:    s.Condition,
+		When:         when,
+	}, nil
+}
+
+// ValidateSteps checks the steps are unique, their scripts exist and their
+// needs are known steps without cycle
+func ValidateSteps(steps []Step) error {
+	index := make(map[string]int, len(steps))
+	for i, s := range steps {
+		if s.Name == "" {
+			return fmt.Errorf("step %d has no name", i)
+		}
+		if _, ok := index[s.Name]; ok {
+			return fmt.Errorf("step %s declared twice", s.Name)
+		}
+		index[s.Name] = i
+		if info, err := os.Stat(s.Run); err != nil || info.IsDir() {
+			return fmt.Errorf("script %q of step %s not found", s.Run, s.Name)
+		}
+		if s.Retries < 0 || s.Timeout < 0 || s.Backoff < 0 {
+			return fmt.Errorf("negative option of step %s", s.Name)
+		}
+		if _, err := s.GetScript(); err != nil {
+			return fmt.Errorf("step %s: %w", s.Name, err)
+		}
+	}
+	for _, s := range steps {
+		for _, need := range s.Needs {
+			if _, ok := index[need]; !ok {
+				return fmt.Errorf("step %s needs unknown step %s", s.Name, need)
+			}
+		}
+	}
+	// depth first search, a step met again while visiting its needs is a cycle
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(steps))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		path = append(path, steps[i].Name)
+		switch marks[i] {
+		case visiting:
+			return fmt.Errorf("steps cycle %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		marks[i] = visiting
+		for _, need := range steps[i].Needs {
+			if err := visit(index[need], path); err != nil {
+				return err
+			}
+		}
+		marks[i] = visited
+		return nil
+	}
+	for i := range steps {
+		if err := visit(i, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
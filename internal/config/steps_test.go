@@ -0,0 +1,84 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+var _ = Describe("Steps", func() {
+	var (
+		dir    string
+		script string
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "steps")
+		Expect(err).To(Succeed())
+		script = filepath.Join(dir, "step.sh")
+		Expect(os.WriteFile(script, []byte(fileTest), 0755)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("validates a DAG", func() {
+		Expect(config.ValidateSteps([]config.Step{
+			{Name: "build", Run: script},
+			{Name: "test", Run: script, Needs: []string{"build"}},
+			{Name: "lint", Run: script, Needs: []string{"build"}},
+			{Name: "deploy", Run: script, Needs: []string{"test", "lint"}, Condition: "$.deploy"},
+		})).To(Succeed())
+	})
+	It("fails on cycle", func() {
+		err := config.ValidateSteps([]config.Step{
+			{Name: "a", Run: script, Needs: []string{"c"}},
+			{Name: "b", Run: script, Needs: []string{"a"}},
+			{Name: "c", Run: script, Needs: []string{"b"}},
+		})
+		Expect(err).To(MatchError(ContainSubstring("steps cycle a -> c -> b -> a")))
+		err = config.ValidateSteps([]config.Step{{Name: "a", Run: script, Needs: []string{"a"}}})
+		Expect(err).To(MatchError(ContainSubstring("cycle")))
+	})
+	It("fails on invalid steps", func() {
+		for _, steps := range [][]config.Step{
+			{{Run: script}},
+			{{Name: "a", Run: script}, {Name: "a", Run: script}},
+			{{Name: "a", Run: filepath.Join(dir, "missing.sh")}},
+			{{Name: "a", Run: script, Needs: []string{"b"}}},
+			{{Name: "a", Run: script, Retries: -1}},
+			{{Name: "a", Run: script, Condition: "deploy"}},
+		} {
+			Expect(config.ValidateSteps(steps)).ToNot(Succeed())
+		}
+	})
+	It("keeps the run options of the step script", func() {
+		s, err := config.Step{Name: "a", Run: script, Retries: 2, AllowFailure: true, Condition: "$.a"}.GetScript()
+		Expect(err).To(Succeed())
+		Expect(s.Path).To(Equal(script))
+		Expect(s.Name).To(Equal("step.sh"))
+		Expect(s.Retries).To(Equal(2))
+		Expect(s.AllowFailure).To(BeTrue())
+		Expect(s.When).To(Equal(&config.Condition{Path: "$.a"}))
+	})
+})
@@ -17,21 +17,117 @@ Created on 20/03/2021
 
 package config
 
+import "time"
+
 type Hook struct {
 	URL   string `json:"url"  yaml:"url"`
 	Scope string `json:"scope" yaml:"scope"`
 }
 
 type Config struct {
+	// Listen is the address the server listens on
+	Listen           string `json:"listen" yaml:"listen"`
 	PreScriptFolder  string `json:"pre_script_folder" yaml:"pre_script_folder"`
 	MainScriptFolder string `json:"main_script_folder" yaml:"main_script_folder"`
 	PostScriptFolder string `json:"post_script_folder" yaml:"post_script_folder"`
 	Hooks            []Hook `json:"hooks" yaml:"hooks"`
+	// HookLegacyLog adds the legacy log message to the hook payload
+	HookLegacyLog bool `json:"hook_legacy_log" yaml:"hook_legacy_log"`
+
+	// FinallyScriptFolder holds the scripts run whatever the outcome of the job
+	FinallyScriptFolder string `json:"finally_script_folder" yaml:"finally_script_folder"`
+	// Steps are run as a DAG instead of the main scripts
+	Steps []Step `json:"steps" yaml:"steps"`
+	// Pipelines are run on POST /process/:pipeline
+	Pipelines []Pipeline `json:"pipelines" yaml:"pipelines"`
+
+	// Timeout bounds the duration of a whole job, 0 means no limit
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// ScriptTimeout bounds the duration of each script, 0 means no limit
+	ScriptTimeout time.Duration `json:"script_timeout" yaml:"script_timeout"`
+
+	// Concurrency is the number of jobs run at the same time
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+	// QueueSize is the number of jobs waiting for a free slot
+	QueueSize int `json:"queue_size" yaml:"queue_size"`
+
+	// LogBufferSize is the number of script output lines kept per job
+	LogBufferSize int `json:"log_buffer_size" yaml:"log_buffer_size"`
+
+	// JobRetention is how long the finished jobs are kept
+	JobRetention time.Duration `json:"job_retention" yaml:"job_retention"`
+	// MaxFinishedJobs is the number of finished jobs kept
+	MaxFinishedJobs int `json:"max_finished_jobs" yaml:"max_finished_jobs"`
+
+	// LockKeyPath is the path into the payload of the key serializing the jobs
+	LockKeyPath string `json:"lock_key_path" yaml:"lock_key_path"`
+
+	// CleanupScripts are the names of the post scripts still run when a job is cancelled
+	CleanupScripts []string `json:"cleanup_scripts" yaml:"cleanup_scripts"`
+
+	// PayloadModes are the ways the payload is delivered to the scripts
+	PayloadModes []string `json:"payload_modes" yaml:"payload_modes"`
+	// PayloadEnvPrefix is the prefix of the payload environment variables
+	PayloadEnvPrefix string `json:"payload_env_prefix" yaml:"payload_env_prefix"`
+
+	// Interpreter runs the scripts, bash by default, shebang executes them directly
+	Interpreter string `json:"interpreter" yaml:"interpreter"`
+
+	// WorkspaceDir is the folder where the job workspaces are created
+	WorkspaceDir string `json:"workspace_dir" yaml:"workspace_dir"`
+	// WorkspaceRetention keeps the workspace of the failed jobs, 0 removes it at once
+	WorkspaceRetention time.Duration `json:"workspace_retention" yaml:"workspace_retention"`
+
+	// ArtifactDir is the folder where the job artifacts are stored
+	ArtifactDir string `json:"artifact_dir" yaml:"artifact_dir"`
+	// ArtifactMaxSize is the maximum size in bytes of the artifacts of a job
+	ArtifactMaxSize int64 `json:"artifact_max_size" yaml:"artifact_max_size"`
+	// ArtifactRetention is how long the artifacts are kept
+	ArtifactRetention time.Duration `json:"artifact_retention" yaml:"artifact_retention"`
+
+	// Schema is the JSON Schema file the payloads are validated against
+	Schema string `json:"schema" yaml:"schema"`
 }
 
-var (
-	config     = new(Config)
-	preScript  []string
-	mainScript []string
-	postScript []string
+const (
+	// DefaultListen is the listen address used when not set
+	DefaultListen = ":8080"
+	// DefaultConcurrency is the concurrency used when not set
+	DefaultConcurrency = 4
+	// DefaultQueueSize is the queue size used when not set
+	DefaultQueueSize = 100
+	// DefaultLogBufferSize is the log buffer size used when not set
+	DefaultLogBufferSize = 1000
+	// DefaultJobRetention is the finished jobs retention used when not set
+	DefaultJobRetention = 24 * time.Hour
+	// DefaultMaxFinishedJobs is the number of finished jobs kept when not set
+	DefaultMaxFinishedJobs = 1000
+	// DefaultPayloadEnvPrefix is the payload environment variables prefix used when not set
+	DefaultPayloadEnvPrefix = "PAYLOAD_"
+	// DefaultArtifactMaxSize is the artifacts maximum size used when not set
+	DefaultArtifactMaxSize = 100 << 20
+	// DefaultArtifactRetention is the artifacts retention used when not set
+	DefaultArtifactRetention = 24 * time.Hour
+)
+
+const (
+	// PayloadModeFile passes the path of the YAML payload file as argument
+	PayloadModeFile = "file"
+	// PayloadModeEnv exports the flattened payload as environment variables
+	PayloadModeEnv = "env"
+	// PayloadModeStdin writes the JSON payload on the standard input
+	PayloadModeStdin = "stdin"
+	// PayloadModeJSON writes a JSON payload file alongside the YAML one
+	PayloadModeJSON = "json"
+)
+
+const (
+	// InterpreterShebang executes the script directly
+	InterpreterShebang = "shebang"
+	// InterpreterBash runs the script with bash
+	InterpreterBash = "bash"
+	// InterpreterSh runs the script with sh
+	InterpreterSh = "sh"
+	// InterpreterPython runs the script with python3
+	InterpreterPython = "python"
 )
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/w6d-io/x/logx"
+)
+
+// ReloadDelay is the quiet period after the last change before reloading, so
+// a batch of changes gives a single reload
+var ReloadDelay = time.Second
+
+// Watch reloads the configuration on the changes of its file and of the
+// script folders until the context is done
+func Watch(ctx context.Context) error {
+	log := logx.WithName(ctx, "Config.Watch")
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = w.Close() }()
+	watched := make(map[string]bool)
+	rewatch := func() {
+		folders := current().folders()
+		for folder := range watched {
+			if !folders[folder] {
+				_ = w.Remove(folder)
+				delete(watched, folder)
+			}
+		}
+		for folder := range folders {
+			if watched[folder] {
+				continue
+			}
+			if err := w.Add(folder); err != nil {
+				log.Error(err, "watch folder failed", "folder", folder)
+				continue
+			}
+			watched[folder] = true
+		}
+	}
+	rewatch()
+	log.Info("watch the configuration", "revision", current().Revision, "folders", len(watched))
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			log.V(1).Info("change", "name", event.Name, "op", event.Op.String())
+			reload = time.After(ReloadDelay)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error(err, "watch failed")
+		case <-reload:
+			reload = nil
+			_ = Reload()
+			rewatch()
+		}
+	}
+}
+
+// folders returns the folders of the configuration file and of the scripts of
+// the snapshot
+func (s *Snapshot) folders() map[string]bool {
+	folders := make(map[string]bool)
+	add := func(path string, file bool) {
+		if path == "" {
+			return
+		}
+		if file {
+			path = filepath.Dir(path)
+		}
+		folders[filepath.Clean(path)] = true
+	}
+	add(CfgFile, true)
+	add(s.config.Schema, true)
+	for _, step := range s.config.Steps {
+		add(step.Run, true)
+	}
+	add(s.config.PreScriptFolder, false)
+	add(s.config.MainScriptFolder, false)
+	add(s.config.PostScriptFolder, false)
+	add(s.config.FinallyScriptFolder, false)
+	for _, p := range s.pipelines {
+		add(p.Schema, true)
+		for _, step := range p.Steps {
+			add(step.Run, true)
+		}
+		add(p.PreScriptFolder, false)
+		add(p.MainScriptFolder, false)
+		add(p.PostScriptFolder, false)
+		add(p.FinallyScriptFolder, false)
+	}
+	return folders
+}
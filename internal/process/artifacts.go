@@ -0,0 +1,201 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/w6d-io/x/logx"
+)
+
+const (
+	// ArtifactsFolder is the folder of the workspace where the scripts drop
+	// the files to keep
+	ArtifactsFolder = "artifacts"
+	// artifactPrefix is the name prefix of the job artifact folders
+	artifactPrefix = "job-"
+)
+
+// artifactDir returns the folder of the store where the artifacts of the job
+// are stored
+func artifactDir(store, id string) string {
+	return filepath.Join(store, artifactPrefix+url.PathEscape(id))
+}
+
+// collectArtifacts copies the regular files of the artifacts folder into the
+// store until the maximum size is reached, and indexes them
+func (p *Process) collectArtifacts() {
+	log := logx.WithName(nil, "Process.collectArtifacts")
+	if p.ID == "" || p.Workspace == "" {
+		return
+	}
+	s := p.snapshot()
+	dst := artifactDir(s.GetArtifactDir(), p.ID)
+	if err := os.RemoveAll(dst); err != nil {
+		log.Error(err, "remove previous artifacts failed", "id", p.ID)
+		return
+	}
+	src := filepath.Join(p.Workspace, ArtifactsFolder)
+	if _, err := os.Stat(src); err != nil {
+		return
+	}
+	var artifacts []Artifact
+	var total int64
+	maxSize := s.GetArtifactMaxSize()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if total+info.Size() > maxSize {
+			log.Info("artifact skipped, maximum size reached", "id", p.ID, "path", rel, "size", info.Size())
+			return nil
+		}
+		if err := copyFile(path, filepath.Join(dst, rel), info.Size()); err != nil {
+			return err
+		}
+		total += info.Size()
+		artifacts = append(artifacts, Artifact{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		log.Error(err, "collect artifacts failed", "id", p.ID)
+	}
+	p.Artifacts = artifacts
+	if len(artifacts) > 0 && p.executor != nil {
+		p.executor.expireArtifacts(p.ID, dst, s.GetArtifactRetention())
+	}
+}
+
+// expireArtifacts removes the stored artifacts once the retention is over
+func (e *Executor) expireArtifacts(id string, dir string, retention time.Duration) {
+	log := logx.WithName(nil, "Process.expireArtifacts")
+	e.artifactTimersMu.Lock()
+	defer e.artifactTimersMu.Unlock()
+	if t, ok := e.artifactTimers[id]; ok {
+		t.Stop()
+	}
+	var t *time.Timer
+	t = time.AfterFunc(retention, func() {
+		e.artifactTimersMu.Lock()
+		defer e.artifactTimersMu.Unlock()
+		if e.artifactTimers[id] != t {
+			return
+		}
+		delete(e.artifactTimers, id)
+		if err := os.RemoveAll(dir); err != nil {
+			log.Error(err, "remove artifacts failed", "id", id)
+		}
+	})
+	e.artifactTimers[id] = t
+}
+
+func copyFile(src, dst string, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(out, in, size); err != nil && err != io.EOF {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// GetArtifact returns the stored file of the artifact of the job
+func (e *Executor) GetArtifact(id string, path string) (string, Artifact, error) {
+	job, ok := e.registry.Get(id)
+	if !ok {
+		return "", Artifact{}, ErrJobNotFound
+	}
+	path = strings.TrimPrefix(path, "/")
+	for _, a := range job.Artifacts {
+		if a.Path != path {
+			continue
+		}
+		file := filepath.Join(artifactDir(e.source().GetArtifactDir(), id), filepath.FromSlash(a.Path))
+		if _, err := os.Stat(file); err != nil {
+			return "", Artifact{}, ErrArtifactNotFound
+		}
+		return file, a, nil
+	}
+	return "", Artifact{}, ErrArtifactNotFound
+}
+
+// ListArtifacts returns the artifacts of the job still stored
+func (e *Executor) ListArtifacts(id string) ([]Artifact, error) {
+	job, ok := e.registry.Get(id)
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	artifacts := []Artifact{}
+	if _, err := os.Stat(artifactDir(e.source().GetArtifactDir(), id)); err != nil {
+		return artifacts, nil
+	}
+	return append(artifacts, job.Artifacts...), nil
+}
+
+// PruneArtifacts removes the job artifacts stored over the retention, e.g. by
+// a previous run of the service
+func (e *Executor) PruneArtifacts() error {
+	log := logx.WithName(nil, "Process.PruneArtifacts")
+	s := e.source()
+	entries, err := os.ReadDir(s.GetArtifactDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), artifactPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < s.GetArtifactRetention() {
+			continue
+		}
+		dir := filepath.Join(s.GetArtifactDir(), entry.Name())
+		log.V(1).Info("remove artifacts", "dir", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			log.Error(err, "remove artifacts failed", "dir", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Artifacts", func() {
+	var (
+		dir      string
+		c        config.Config
+		executor *process.Executor
+		reload   func(config.Config)
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "artifacts")
+		Expect(err).To(Succeed())
+		writeScript(filepath.Join(dir, "scripts"), "artifacts.sh", "#!/bin/bash\n")
+		c = config.Config{
+			MainScriptFolder: filepath.Join(dir, "scripts"),
+			WorkspaceDir:     dir,
+			ArtifactDir:      filepath.Join(dir, "store"),
+		}
+		var source config.Source
+		source, reload = newSource(c)
+		executor = process.NewExecutor(source, process.NewMemoryRegistry())
+	})
+	AfterEach(func() {
+		executor.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	run := func(id string, script string) *process.Process {
+		writeScript(filepath.Join(dir, "scripts"), "artifacts.sh", "#!/bin/bash\n"+script)
+		p, err := executor.New(id, "")
+		Expect(err).To(Succeed())
+		Expect(executor.Register(id)).To(Succeed())
+		Expect(p.CreateWorkspace()).To(Succeed())
+		_ = p.Execute(context.Background())
+		return p
+	}
+	It("collects the files of the artifacts folder", func() {
+		p := run("../job", `mkdir -p artifacts/diff
+echo manifest > artifacts/manifest.yaml
+echo diff > artifacts/diff/helm.diff
+ln -s /etc/hostname artifacts/link
+`)
+		Expect(p.Artifacts).To(HaveLen(2))
+		Expect(p.Artifacts[0].Path).To(Equal("diff/helm.diff"))
+		Expect(p.Artifacts[1].Path).To(Equal("manifest.yaml"))
+		Expect(p.Artifacts[1].Size).To(Equal(int64(9)))
+
+		file, artifact, err := executor.GetArtifact("../job", "/diff/helm.diff")
+		Expect(err).To(Succeed())
+		Expect(artifact.Path).To(Equal("diff/helm.diff"))
+		Expect(strings.HasPrefix(file, filepath.Join(dir, "store")+string(os.PathSeparator))).To(BeTrue())
+		data, err := os.ReadFile(file)
+		Expect(err).To(Succeed())
+		Expect(string(data)).To(Equal("diff\n"))
+
+		_, _, err = executor.GetArtifact("../job", "link")
+		Expect(err).To(MatchError(process.ErrArtifactNotFound))
+		artifacts, err := executor.ListArtifacts("../job")
+		Expect(err).To(Succeed())
+		Expect(artifacts).To(HaveLen(2))
+	})
+	It("skips the files over the maximum size", func() {
+		c.ArtifactMaxSize = 10
+		reload(c)
+		p := run("job-max", `mkdir artifacts
+echo 12345 > artifacts/a
+echo 12345 > artifacts/b
+`)
+		Expect(p.Artifacts).To(HaveLen(1))
+		Expect(p.Artifacts[0].Path).To(Equal("a"))
+	})
+	It("removes the artifacts after the retention", func() {
+		c.ArtifactRetention = 100 * time.Millisecond
+		reload(c)
+		run("job-retention", "mkdir artifacts\necho test > artifacts/a\n")
+		Eventually(func() error {
+			_, _, err := executor.GetArtifact("job-retention", "a")
+			return err
+		}, 2*time.Second).Should(MatchError(process.ErrArtifactNotFound))
+		artifacts, err := executor.ListArtifacts("job-retention")
+		Expect(err).To(Succeed())
+		Expect(artifacts).To(BeEmpty())
+	})
+	It("returns job not found", func() {
+		_, err := executor.ListArtifacts("unknown")
+		Expect(err).To(MatchError(process.ErrJobNotFound))
+		_, _, err = executor.GetArtifact("unknown", "a")
+		Expect(err).To(MatchError(process.ErrJobNotFound))
+	})
+	It("prunes the artifacts over the retention", func() {
+		run("job-prune", "mkdir artifacts\necho test > artifacts/a\n")
+		Expect(executor.PruneArtifacts()).To(Succeed())
+		_, _, err := executor.GetArtifact("job-prune", "a")
+		Expect(err).To(Succeed())
+		c.ArtifactDir = filepath.Join(dir, "unknown")
+		reload(c)
+		Expect(executor.PruneArtifacts()).To(Succeed())
+	})
+	It("finds the artifacts in the store of the executor configuration", func() {
+		scripts := filepath.Join(dir, "static-scripts")
+		writeScript(scripts, "static.sh", "#!/bin/bash\nmkdir artifacts\necho a > artifacts/a.txt\n")
+		static := newExecutor(config.Config{
+			MainScriptFolder: scripts,
+			WorkspaceDir:     dir,
+			ArtifactDir:      filepath.Join(dir, "static"),
+		})
+		defer static.Close()
+		p, err := static.New("job-static", "")
+		Expect(err).To(Succeed())
+		Expect(static.Register("job-static")).To(Succeed())
+		Expect(p.CreateWorkspace()).To(Succeed())
+		Expect(p.Execute(context.Background())).To(Succeed())
+		file, _, err := static.GetArtifact("job-static", "a.txt")
+		Expect(err).To(Succeed())
+		Expect(strings.HasPrefix(file, filepath.Join(dir, "static")+string(os.PathSeparator))).To(BeTrue())
+		artifacts, err := static.ListArtifacts("job-static")
+		Expect(err).To(Succeed())
+		Expect(artifacts).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+
+	"github.com/w6d-io/x/logx"
+)
+
+// Cancel stops the job. A job not started yet is cancelled as soon as it starts
+func (e *Executor) Cancel(id string) error {
+	log := logx.WithName(nil, "Process.Cancel")
+	job, ok := e.registry.Get(id)
+	if !ok {
+		return ErrJobNotFound
+	}
+	if job.State.Finished() {
+		return ErrJobFinished
+	}
+	if t := e.pool.remove(id); t != nil {
+		log.Info("cancel queued job", "id", id)
+		t.done <- t.p.Cancelled(t.ctx, t.arg...)
+		return nil
+	}
+	e.runningMu.Lock()
+	defer e.runningMu.Unlock()
+	if cancel, ok := e.running[id]; ok {
+		log.Info("cancel running job", "id", id)
+		cancel(ErrCancelled)
+		return nil
+	}
+	log.Info("cancel pending job", "id", id)
+	e.pending[id] = true
+	return nil
+}
+
+// clearPending forgets the cancellation requested before the job started
+func (e *Executor) clearPending(id string) {
+	e.runningMu.Lock()
+	defer e.runningMu.Unlock()
+	delete(e.pending, id)
+}
+
+// track makes the process cancellable through the Cancel of its executor
+func (p *Process) track(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	e := p.executor
+	if e == nil || p.ID == "" {
+		return ctx, func() { cancel(nil) }
+	}
+	e.runningMu.Lock()
+	e.running[p.ID] = cancel
+	if e.pending[p.ID] {
+		delete(e.pending, p.ID)
+		cancel(ErrCancelled)
+	}
+	e.runningMu.Unlock()
+	return ctx, func() {
+		e.runningMu.Lock()
+		delete(e.running, p.ID)
+		e.runningMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Cancelled runs the cleanup post scripts not run yet and the finally scripts
+// when the process has started, then records and notifies the cancellation
+func (p *Process) Cancelled(ctx context.Context, arg ...string) error {
+	log := logx.WithName(ctx, "Process.Cancelled")
+	log.Info("process cancelled", "id", p.ID)
+	var scripts []string
+	if pl := p.pipeline(); pl != nil && len(p.ran) != 0 {
+		for _, script := range pl.GetCleanupScript() {
+			if !p.ran[script] {
+				scripts = append(scripts, script)
+			}
+		}
+	}
+	if len(scripts) != 0 {
+		p.setState(StatePost, nil)
+		if err := p.LoopProcess(context.WithoutCancel(ctx), scripts, arg...); err != nil {
+			log.Error(err, "cleanup failed")
+		}
+	}
+	e := NewError(ErrCancelled, CodeCancelled, "process cancelled")
+	if len(p.ran) != 0 {
+		if err := p.Finally(ctx, e, arg...); err != nil {
+			log.Error(err, "finally failed")
+		}
+	}
+	p.setState(StateCancelled, ErrCancelled)
+	p.Notify(p.ID, "process-cancelled", e)
+	return e
+}
+
+// markRun records the script as run by the process
+func (p *Process) markRun(script string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ran == nil {
+		p.ran = make(map[string]bool)
+	}
+	p.ran[script] = true
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Cancel", func() {
+	var (
+		dir      string
+		err      error
+		executor *process.Executor
+	)
+	script := func(stage, name, content string) string {
+		return writeScript(filepath.Join(dir, stage), name, content)
+	}
+	state := func(id string) process.State {
+		job, _ := executor.GetJob(id)
+		return job.State
+	}
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "cancel_dir")
+		Expect(err).To(Succeed())
+		executor = newExecutor(config.Config{MainScriptFolder: filepath.Dir(script("main", "main.sh", successTest))})
+	})
+	AfterEach(func() {
+		executor.Close()
+		process.GracePeriod = 10 * time.Second
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("fails for unknown job", func() {
+		Expect(executor.Cancel("unknown")).To(MatchError(process.ErrJobNotFound))
+	})
+	It("fails for finished job", func() {
+		Expect(executor.Register("done")).To(Succeed())
+		Expect(execute(executor, "done")).To(Succeed())
+		Expect(executor.Cancel("done")).To(MatchError(process.ErrJobFinished))
+	})
+	It("cancels a job not started yet", func() {
+		Expect(executor.Register("pending")).To(Succeed())
+		Expect(executor.Cancel("pending")).To(Succeed())
+		err := execute(executor, "pending")
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*process.Error).GetStatusCode()).To(Equal(process.CodeCancelled))
+		job, _ := executor.GetJob("pending")
+		Expect(job.State).To(Equal(process.StateCancelled))
+		Expect(job.Outputs).To(BeEmpty())
+	})
+	It("forgets the cancellation of an unregistered job", func() {
+		Expect(executor.Register("pending")).To(Succeed())
+		Expect(executor.Cancel("pending")).To(Succeed())
+		Expect(executor.Unregister("pending")).To(Succeed())
+		Expect(executor.Register("pending")).To(Succeed())
+		Expect(execute(executor, "pending")).To(Succeed())
+		Expect(state("pending")).To(Equal(process.StateSucceeded))
+	})
+	It("stops the running script and runs the cleanup scripts", func() {
+		script("pre", "pre.sh", successTest)
+		script("main", "main.sh", "#!/bin/bash\nsleep 10 &\nwait\n")
+		script("main", "skipped.sh", successTest)
+		script("post", "other.sh", successTest)
+		script("post", "cleanup.sh", successTest)
+		executor.Close()
+		executor = newExecutor(config.Config{
+			PreScriptFolder:  filepath.Join(dir, "pre"),
+			MainScriptFolder: filepath.Join(dir, "main"),
+			PostScriptFolder: filepath.Join(dir, "post"),
+			CleanupScripts:   []string{"cleanup.sh"},
+		})
+		Expect(executor.Register("running")).To(Succeed())
+		errc := make(chan error, 1)
+		go func() {
+			errc <- execute(executor, "running")
+		}()
+		Eventually(func() process.State { return state("running") }, 5*time.Second).Should(Equal(process.StateMain))
+		time.Sleep(100 * time.Millisecond)
+		Expect(executor.Cancel("running")).To(Succeed())
+		var err error
+		Eventually(errc, 5*time.Second).Should(Receive(&err))
+		Expect(err.(*process.Error).GetStatusCode()).To(Equal(process.CodeCancelled))
+		job, _ := executor.GetJob("running")
+		Expect(job.State).To(Equal(process.StateCancelled))
+		Expect(job.Outputs).To(HaveLen(3))
+		Expect(job.Outputs[1].Name).To(Equal("main.sh"))
+		Expect(job.Outputs[1].Status).To(Equal(process.StatusCancelled))
+		Expect(job.Outputs[2].Name).To(Equal("cleanup.sh"))
+		Expect(job.Outputs[2].Status).To(Equal("succeeded"))
+	})
+	It("kills the script ignoring SIGTERM after the grace period", func() {
+		process.GracePeriod = 200 * time.Millisecond
+		script("main", "main.sh", "#!/bin/bash\ntrap '' TERM\nfor i in $(seq 50); do sleep 0.1; done\n")
+		Expect(executor.Register("stubborn")).To(Succeed())
+		errc := make(chan error, 1)
+		go func() {
+			errc <- execute(executor, "stubborn")
+		}()
+		Eventually(func() process.State { return state("stubborn") }, 5*time.Second).Should(Equal(process.StateMain))
+		time.Sleep(100 * time.Millisecond)
+		Expect(executor.Cancel("stubborn")).To(Succeed())
+		Eventually(errc, 3*time.Second).Should(Receive(HaveOccurred()))
+		Expect(state("stubborn")).To(Equal(process.StateCancelled))
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+// Lookup returns the scalar value at the JSONPath like path ($.a.b[0].c) of
+// the payload
+func Lookup(payload map[string]interface{}, path string) (string, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	var value interface{} = payload
+	for _, field := range strings.Split(path, ".") {
+		name, indexes, ok := parseField(field)
+		if !ok {
+			return "", false
+		}
+		if name != "" {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			if value, ok = m[name]; !ok {
+				return "", false
+			}
+		}
+		for _, index := range indexes {
+			a, ok := value.([]interface{})
+			if !ok || index < 0 || index >= len(a) {
+				return "", false
+			}
+			value = a[index]
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case float64, bool, int:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// parseField splits name[0][1] into its name and indexes
+func parseField(field string) (string, []int, bool) {
+	name, rest, _ := strings.Cut(field, "[")
+	if rest == "" {
+		return name, nil, name != ""
+	}
+	var indexes []int
+	for _, part := range strings.Split(rest, "[") {
+		index, err := strconv.Atoi(strings.TrimSuffix(part, "]"))
+		if err != nil || !strings.HasSuffix(part, "]") {
+			return "", nil, false
+		}
+		indexes = append(indexes, index)
+	}
+	return name, indexes, true
+}
+
+// Match returns whether the payload meets the condition
+func Match(c *config.Condition, payload map[string]interface{}) bool {
+	if c == nil {
+		return true
+	}
+	value, ok := Lookup(payload, c.Path)
+	switch c.Operator {
+	case "==":
+		return value == c.Value
+	case "!=":
+		return value != c.Value
+	}
+	set := ok && value != "false" && value != "0"
+	return set != c.Not
+}
+
+// skipReason returns why the script is not run, empty when it has to run
+func (p *Process) skipReason(s config.Script) string {
+	if !s.IsEnabled() {
+		return "disabled"
+	}
+	if s.When == nil {
+		return ""
+	}
+	p.mu.Lock()
+	if p.values == nil {
+		p.values = make(map[string]interface{})
+		if len(p.Payload) != 0 {
+			_ = json.Unmarshal(p.Payload, &p.values)
+		}
+	}
+	p.mu.Unlock()
+	if !Match(s.When, p.values) {
+		return "condition " + s.Condition + " not met"
+	}
+	return ""
+}
@@ -24,10 +24,31 @@ func (e *Error) Error() string {
 	return e.Message + " : " + e.Cause.Error()
 }
 
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
 func (e *Error) GetStatusCode() int {
 	return e.Code
 }
 
+// GetStage returns the stage matching the error code
+func (e *Error) GetStage() string {
+	switch e.Code {
+	case CodePreProcess:
+		return "pre"
+	case CodeMainProcess:
+		return "main"
+	case CodePostProcess:
+		return "post"
+	case CodeCancelled:
+		return "cancelled"
+	case CodeFinallyProcess:
+		return "finally"
+	}
+	return ""
+}
+
 //func (e *Error) GetResponse() Response {
 //	return Response{
 //		Status:  "error",
@@ -36,6 +57,14 @@ func (e *Error) GetStatusCode() int {
 //	}
 //}
 
+func (e *TimeoutError) Error() string {
+	return "script " + e.Script + " timed out : " + e.Cause.Error()
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Cause
+}
+
 func NewError(cause error, code int, message string) error {
 	return &Error{
 		Code:    code,
@@ -48,5 +48,12 @@ var _ = Describe("Error", func() {
 			Expect(err.GetStatusCode()).To(Equal(500))
 			//Expect(err.GetResponse()).To(Equal(Response{Status: "error", Message: err.Message, Error: err.Cause}))
 		})
+		It("get stage", func() {
+			Expect((&process.Error{Code: process.CodePreProcess}).GetStage()).To(Equal("pre"))
+			Expect((&process.Error{Code: process.CodeMainProcess}).GetStage()).To(Equal("main"))
+			Expect((&process.Error{Code: process.CodePostProcess}).GetStage()).To(Equal("post"))
+			Expect((&process.Error{Code: process.CodeFinallyProcess}).GetStage()).To(Equal("finally"))
+			Expect((&process.Error{Code: 500}).GetStage()).To(BeEmpty())
+		})
 	})
 })
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+// Executor creates the processes from the configuration of its source, runs
+// them in its pool and keeps track of their jobs, logs and artifacts
+type Executor struct {
+	source   config.Source
+	pool     *Pool
+	registry Registry
+	// registerMu serializes the registrations of the same id
+	registerMu sync.Mutex
+
+	runningMu sync.Mutex
+	running   map[string]context.CancelCauseFunc
+	pending   map[string]bool
+
+	logsMu sync.Mutex
+	logs   map[string]*LogBuffer
+
+	artifactTimersMu sync.Mutex
+	artifactTimers   map[string]*time.Timer
+}
+
+// NewExecutor returns an executor of the configuration source recording the
+// jobs in the registry, with a pool sized from the configuration
+func NewExecutor(source config.Source, registry Registry) *Executor {
+	s := source()
+	return &Executor{
+		source:         source,
+		pool:           NewPool(s.GetConcurrency(), s.GetQueueSize()),
+		registry:       registry,
+		running:        make(map[string]context.CancelCauseFunc),
+		pending:        make(map[string]bool),
+		logs:           make(map[string]*LogBuffer),
+		artifactTimers: make(map[string]*time.Timer),
+	}
+}
+
+// GetSnapshot returns the configuration the next processes are created from
+func (e *Executor) GetSnapshot() *config.Snapshot {
+	return e.source()
+}
+
+// New returns a process of the named pipeline with its timeouts and
+// concurrency, the default pipeline when the name is empty. It fails with
+// ErrPipelineNotFound on unknown name
+func (e *Executor) New(id, name string) (*Process, error) {
+	p, err := newProcess(e.source, id, name)
+	if err != nil {
+		return nil, err
+	}
+	p.executor = e
+	return p, nil
+}
+
+// Submit queues the process in the pool of the executor, first sized from
+// the configuration in case it was reloaded
+func (e *Executor) Submit(p *Process, arg ...string) (<-chan error, error) {
+	s := e.source()
+	e.pool.Resize(s.GetConcurrency(), s.GetQueueSize())
+	return e.pool.Submit(context.Background(), p, arg...)
+}
+
+// Close stops the pool of the executor
+func (e *Executor) Close() {
+	e.pool.Close()
+}
+
+// newProcess returns a process of the named pipeline of the configuration
+// source, not tracked by any executor
+func newProcess(source config.Source, id, name string) (*Process, error) {
+	pl, ok := source().GetPipeline(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPipelineNotFound, name)
+	}
+	return &Process{
+		ID:            id,
+		Pipeline:      name,
+		Timeout:       pl.GetTimeout(),
+		ScriptTimeout: pl.GetScriptTimeout(),
+		Concurrency:   pl.Concurrency,
+		source:        source,
+	}, nil
+}
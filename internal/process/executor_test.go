@@ -0,0 +1,134 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Executor", func() {
+	var (
+		dirs      []string
+		executors []*process.Executor
+	)
+	BeforeEach(func() {
+		dirs, executors = nil, nil
+		for i := 0; i < 2; i++ {
+			dir, err := os.MkdirTemp("", "executor")
+			Expect(err).To(Succeed())
+			script := "#!/bin/bash\ntouch \"$(dirname \"$0\")/ran\"\n"
+			Expect(os.WriteFile(filepath.Join(dir, "main.sh"), []byte(script), 0755)).To(Succeed())
+			s, err := config.New(config.Config{MainScriptFolder: dir})
+			Expect(err).To(Succeed())
+			dirs = append(dirs, dir)
+			executors = append(executors, process.NewExecutor(config.Static(s), process.NewMemoryRegistry()))
+		}
+	})
+	AfterEach(func() {
+		for i := range executors {
+			executors[i].Close()
+			Expect(os.RemoveAll(dirs[i])).To(Succeed())
+		}
+	})
+	It("runs the scripts of its own configuration", func() {
+		p, err := executors[0].New("executor-first", "")
+		Expect(err).To(Succeed())
+		done, err := executors[0].Submit(p)
+		Expect(err).To(Succeed())
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(filepath.Join(dirs[0], "ran")).To(BeARegularFile())
+		Expect(filepath.Join(dirs[1], "ran")).NotTo(BeAnExistingFile())
+		Expect(config.GetSnapshot().GetMainScript()).To(BeEmpty())
+	})
+	It("fails on unknown pipeline", func() {
+		_, err := executors[1].New("executor-unknown", "unknown")
+		Expect(errors.Is(err, process.ErrPipelineNotFound)).To(BeTrue())
+	})
+	It("refuses the processes once closed", func() {
+		p, err := executors[1].New("executor-closed", "")
+		Expect(err).To(Succeed())
+		executors[1].Close()
+		_, err = executors[1].Submit(p)
+		Expect(err).To(MatchError(process.ErrPoolClosed))
+	})
+	It("records the jobs in its registry", func() {
+		r := process.NewMemoryRegistry()
+		e := process.NewExecutor(executors[0].GetSnapshot, r)
+		defer e.Close()
+		Expect(e.Register("executor-registry")).To(Succeed())
+		job, ok := r.Get("executor-registry")
+		Expect(ok).To(BeTrue())
+		Expect(job.State).To(Equal(process.StateQueued))
+		_, ok = executors[0].GetJob("executor-registry")
+		Expect(ok).To(BeFalse())
+	})
+	It("returns the snapshot of its source", func() {
+		Expect(executors[0].GetSnapshot()).NotTo(BeIdenticalTo(config.GetSnapshot()))
+		Expect(executors[0].GetSnapshot().GetMainScript()).To(HaveLen(1))
+	})
+})
+
+// newProcess returns a new process of the default pipeline tracked by e
+func newProcess(e *process.Executor, id string) *process.Process {
+	p, err := e.New(id, "")
+	Expect(err).To(Succeed())
+	return p
+}
+
+// execute runs a new process of the default pipeline tracked by e
+func execute(e *process.Executor, id string, arg ...string) error {
+	return newProcess(e, id).Execute(context.Background(), arg...)
+}
+
+// writeScript writes the script into the folder, created when missing
+func writeScript(folder, name, content string) string {
+	Expect(os.MkdirAll(folder, 0755)).To(Succeed())
+	filename := filepath.Join(folder, name)
+	Expect(os.WriteFile(filename, []byte(content), 0755)).To(Succeed())
+	return filename
+}
+
+// newSnapshot returns the snapshot of the configuration
+func newSnapshot(c config.Config) *config.Snapshot {
+	s, err := config.New(c)
+	Expect(err).To(Succeed())
+	return s
+}
+
+// newExecutor returns an executor of the snapshot of the configuration
+func newExecutor(c config.Config) *process.Executor {
+	return process.NewExecutor(config.Static(newSnapshot(c)), process.NewMemoryRegistry())
+}
+
+// newSource returns the source of the snapshot of the configuration and the
+// function reloading it with another one
+func newSource(c config.Config) (config.Source, func(config.Config)) {
+	var active atomic.Pointer[config.Snapshot]
+	active.Store(newSnapshot(c))
+	return active.Load, func(c config.Config) { active.Store(newSnapshot(c)) }
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+	"errors"
+
+	"github.com/w6d-io/x/logx"
+)
+
+const (
+	// StatusEnv holds the outcome of the job in the finally scripts
+	StatusEnv = "PROCESS_STATUS"
+	// FailedStageEnv holds the failing stage in the finally scripts
+	FailedStageEnv = "PROCESS_FAILED_STAGE"
+	// ErrorEnv holds the error of the job in the finally scripts
+	ErrorEnv = "PROCESS_ERROR"
+)
+
+// Finally runs every finally script whatever the outcome of the process, even
+// once the job is timed out or cancelled. The outcome given by err is set in
+// the environment of the scripts. It returns the first failure
+func (p *Process) Finally(ctx context.Context, err error, arg ...string) error {
+	log := logx.WithName(ctx, "Process.Finally")
+	scripts := p.pipeline().GetFinallyScript()
+	if len(scripts) == 0 {
+		return nil
+	}
+	p.setState(StateFinally, nil)
+	status, stage, message := "succeeded", "", ""
+	if err != nil {
+		status, message = "failed", err.Error()
+		var e *Error
+		if errors.As(err, &e) {
+			stage = e.GetStage()
+		}
+		if errors.Is(err, ErrCancelled) {
+			status = "cancelled"
+		}
+	}
+	p.Env = append(append([]string{}, p.Env...),
+		StatusEnv+"="+status,
+		FailedStageEnv+"="+stage,
+		ErrorEnv+"="+message,
+	)
+	ctx = context.WithoutCancel(ctx)
+	var first error
+	for _, script := range scripts {
+		if err := p.LoopProcess(ctx, []string{script}, arg...); err != nil {
+			log.Error(err, "finally script failed", "script", script)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Finally", func() {
+	var (
+		dir      string
+		executor *process.Executor
+	)
+	script := func(stage, name string, content string) string {
+		return writeScript(filepath.Join(dir, stage), name, "#!/bin/bash\n"+content)
+	}
+	load := func() {
+		executor = newExecutor(config.Config{
+			MainScriptFolder:    filepath.Join(dir, "main"),
+			PostScriptFolder:    filepath.Join(dir, "post"),
+			FinallyScriptFolder: filepath.Join(dir, "finally"),
+		})
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "finally")
+		Expect(err).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "post"), 0755)).To(Succeed())
+	})
+	AfterEach(func() {
+		executor.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	env := `echo -n "$PROCESS_STATUS|$PROCESS_FAILED_STAGE|$PROCESS_ERROR"` + "\n"
+	It("runs after a success", func() {
+		script("main", "main.sh", "exit 0\n")
+		script("finally", "finally.sh", env)
+		load()
+		p := newProcess(executor, "finally-success")
+		Expect(p.Execute(context.Background())).To(Succeed())
+		Expect(p.Outputs).To(HaveLen(2))
+		Expect(p.Outputs[1].Stdout).To(Equal("succeeded||"))
+	})
+	It("runs after a failure with the failing stage", func() {
+		script("main", "main.sh", "exit 1\n")
+		script("post", "post.sh", "exit 0\n")
+		script("finally", "finally.sh", env)
+		load()
+		p := newProcess(executor, "finally-failure")
+		err := p.Execute(context.Background())
+		Expect(err).To(HaveOccurred())
+		var e *process.Error
+		Expect(errors.As(err, &e)).To(BeTrue())
+		Expect(e.Code).To(Equal(process.CodeMainProcess))
+		Expect(p.Outputs).To(HaveLen(2))
+		Expect(p.Outputs[1].Name).To(Equal("finally.sh"))
+		Expect(p.Outputs[1].Stdout).To(Equal("failed|main|main process failed : exit status 1"))
+	})
+	It("runs every script and fails a succeeded job", func() {
+		script("main", "main.sh", "exit 0\n")
+		script("finally", "01-finally.sh", "exit 1\n")
+		script("finally", "02-finally.sh", "exit 0\n")
+		load()
+		Expect(executor.Register("finally-fails")).To(Succeed())
+		p := newProcess(executor, "finally-fails")
+		err := p.Execute(context.Background())
+		var e *process.Error
+		Expect(errors.As(err, &e)).To(BeTrue())
+		Expect(e.Code).To(Equal(process.CodeFinallyProcess))
+		Expect(e.GetStage()).To(Equal("finally"))
+		Expect(p.Outputs).To(HaveLen(3))
+		Expect(p.Outputs[2].Status).To(Equal("succeeded"))
+		job, ok := executor.GetJob("finally-fails")
+		Expect(ok).To(BeTrue())
+		Expect(job.State).To(Equal(process.StateFailed))
+	})
+	It("runs after a cancellation", func() {
+		script("main", "main.sh", "exit 0\n")
+		script("post", "post.sh", "exit 0\n")
+		script("finally", "finally.sh", env)
+		load()
+		p := newProcess(executor, "finally-cancel")
+		Expect(p.MainProcess(context.Background())).To(Succeed())
+		err := p.Cancelled(context.Background())
+		Expect(errors.Is(err, process.ErrCancelled)).To(BeTrue())
+		Expect(p.Outputs).To(HaveLen(2))
+		Expect(p.Outputs[1].Stdout).To(Equal("cancelled|cancelled|process cancelled : cancelled"))
+	})
+})
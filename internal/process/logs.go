@@ -0,0 +1,167 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// LogBuffer keeps the last lines written by the scripts of a job and wakes up
+// the followers on each new line
+type LogBuffer struct {
+	mu     sync.Mutex
+	lines  []Line
+	size   int
+	seq    int
+	closed bool
+	notify chan struct{}
+}
+
+// NewLogBuffer returns a buffer keeping the last size lines
+func NewLogBuffer(size int) *LogBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &LogBuffer{size: size, notify: make(chan struct{})}
+}
+
+// Append adds the line to the buffer, dropping the oldest one when full
+func (b *LogBuffer) Append(line Line) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	line.Seq = b.seq
+	b.seq++
+	if line.Time.IsZero() {
+		line.Time = time.Now()
+	}
+	if len(b.lines) == b.size {
+		b.lines = b.lines[1:]
+	}
+	b.lines = append(b.lines, line)
+	close(b.notify)
+	b.notify = make(chan struct{})
+}
+
+// Since returns the kept lines from the sequence number, the next sequence
+// number, a channel closed on the next change and whether the buffer is closed
+func (b *LogBuffer) Since(seq int) ([]Line, int, <-chan struct{}, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var lines []Line
+	for _, line := range b.lines {
+		if line.Seq >= seq {
+			lines = append(lines, line)
+		}
+	}
+	return lines, b.seq, b.notify, b.closed
+}
+
+// Close marks the end of the logs
+func (b *LogBuffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.notify)
+}
+
+// lineWriter appends each line written to the buffer
+type lineWriter struct {
+	buffer  *LogBuffer
+	script  string
+	stream  string
+	partial []byte
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	if w == nil {
+		return len(data), nil
+	}
+	w.partial = append(w.partial, data...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.buffer.Append(Line{Script: w.script, Stream: w.stream, Text: string(w.partial[:i])})
+		w.partial = w.partial[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush appends the last line not ended by a new line
+func (w *lineWriter) Flush() {
+	if w != nil && len(w.partial) != 0 {
+		w.buffer.Append(Line{Script: w.script, Stream: w.stream, Text: string(w.partial)})
+		w.partial = nil
+	}
+}
+
+// GetLogs returns the log buffer of the job
+func (e *Executor) GetLogs(id string) (*LogBuffer, bool) {
+	e.logsMu.Lock()
+	defer e.logsMu.Unlock()
+	buffer, ok := e.logs[id]
+	return buffer, ok
+}
+
+// closeLogs marks the end of the job logs
+func (e *Executor) closeLogs(id string) {
+	if buffer, ok := e.GetLogs(id); ok {
+		buffer.Close()
+	}
+}
+
+// dropLogs frees the log buffer of the job, closed first to release the
+// followers
+func (e *Executor) dropLogs(id string) {
+	e.logsMu.Lock()
+	defer e.logsMu.Unlock()
+	if buffer, ok := e.logs[id]; ok {
+		buffer.Close()
+		delete(e.logs, id)
+	}
+}
+
+// resetLogs replaces the log buffer of the job by an empty one sized from
+// the configuration of the executor
+func (e *Executor) resetLogs(id string) {
+	e.logsMu.Lock()
+	defer e.logsMu.Unlock()
+	e.logs[id] = NewLogBuffer(e.source().GetLogBufferSize())
+}
+
+// writers returns the writers streaming the script outputs into the job logs
+func (p *Process) writers(script string) (*lineWriter, *lineWriter) {
+	if p.executor == nil {
+		return nil, nil
+	}
+	buffer, ok := p.executor.GetLogs(p.ID)
+	if !ok {
+		return nil, nil
+	}
+	return &lineWriter{buffer: buffer, script: script, stream: "stdout"},
+		&lineWriter{buffer: buffer, script: script, stream: "stderr"}
+}
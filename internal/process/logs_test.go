@@ -0,0 +1,116 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Logs", func() {
+	Context("buffer", func() {
+		It("keeps the last lines", func() {
+			b := process.NewLogBuffer(2)
+			b.Append(process.Line{Text: "1"})
+			b.Append(process.Line{Text: "2"})
+			b.Append(process.Line{Text: "3"})
+			lines, next, _, closed := b.Since(0)
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0].Text).To(Equal("2"))
+			Expect(lines[0].Seq).To(Equal(1))
+			Expect(next).To(Equal(3))
+			Expect(closed).To(BeFalse())
+			lines, _, _, _ = b.Since(2)
+			Expect(lines).To(HaveLen(1))
+		})
+		It("wakes up the followers", func() {
+			b := process.NewLogBuffer(10)
+			_, _, changed, _ := b.Since(0)
+			Expect(changed).ToNot(BeClosed())
+			b.Append(process.Line{Text: "1"})
+			Expect(changed).To(BeClosed())
+			_, _, changed, _ = b.Since(1)
+			b.Close()
+			Expect(changed).To(BeClosed())
+			b.Append(process.Line{Text: "2"})
+			_, next, _, closed := b.Since(0)
+			Expect(next).To(Equal(1))
+			Expect(closed).To(BeTrue())
+		})
+	})
+	Context("job", func() {
+		var (
+			dir      string
+			executor *process.Executor
+		)
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "logs_dir")
+			Expect(err).To(Succeed())
+			writeScript(dir, "logs.sh", "#!/bin/bash\necho out\necho err >&2\nprintf last\n")
+			executor = newExecutor(config.Config{MainScriptFolder: dir})
+		})
+		AfterEach(func() {
+			executor.Close()
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("streams stdout and stderr lines", func() {
+			Expect(executor.Register("logs")).To(Succeed())
+			buffer, ok := executor.GetLogs("logs")
+			Expect(ok).To(BeTrue())
+			Expect(execute(executor, "logs")).To(Succeed())
+			lines, _, _, closed := buffer.Since(0)
+			Expect(closed).To(BeTrue())
+			Expect(lines).To(HaveLen(3))
+			texts := map[string]string{}
+			for _, line := range lines {
+				Expect(line.Script).To(Equal("logs.sh"))
+				Expect(line.Time).To(BeTemporally("~", time.Now(), time.Minute))
+				texts[line.Text] = line.Stream
+			}
+			Expect(texts).To(Equal(map[string]string{"out": "stdout", "err": "stderr", "last": "stdout"}))
+		})
+		It("frees the logs of the evicted jobs", func() {
+			executor.Close()
+			executor = newExecutor(config.Config{MainScriptFolder: dir, MaxFinishedJobs: 1})
+			for _, id := range []string{"1", "2"} {
+				Expect(executor.Register(id)).To(Succeed())
+				Expect(execute(executor, id)).To(Succeed())
+			}
+			_, ok := executor.GetLogs("1")
+			Expect(ok).To(BeFalse())
+			_, ok = executor.GetLogs("2")
+			Expect(ok).To(BeTrue())
+		})
+		It("frees the logs of the unregistered jobs", func() {
+			Expect(executor.Register("logs")).To(Succeed())
+			buffer, _ := executor.GetLogs("logs")
+			Expect(executor.Unregister("logs")).To(Succeed())
+			_, ok := executor.GetLogs("logs")
+			Expect(ok).To(BeFalse())
+			_, _, _, closed := buffer.Since(0)
+			Expect(closed).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// OutputsEnv holds the path of the file where a script writes its variables
+	OutputsEnv = "OUTPUTS"
+	// VariablesEnvPrefix prefixes the variables exported to the following
+	// scripts, so they cannot override PATH, WORKSPACE or the payload
+	VariablesEnvPrefix = "OUTPUT_"
+)
+
+// createOutputs creates the empty variables file of a script
+func (p *Process) createOutputs() (string, error) {
+	f, err := os.CreateTemp(p.Workspace, ".outputs-*")
+	if err != nil {
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// readOutputs returns the variables written by a script then removes the file
+func readOutputs(filename string) (map[string]string, error) {
+	defer func() { _ = os.Remove(filename) }()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := ParseOutputs(data)
+	if err != nil || len(variables) == 0 {
+		return nil, err
+	}
+	return variables, nil
+}
+
+// ParseOutputs returns the variables of a JSON object or of key=value lines.
+// The JSON values that are not strings are kept encoded
+func ParseOutputs(data []byte) (map[string]string, error) {
+	variables := make(map[string]string)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("decode outputs: %w", err)
+		}
+		for k, raw := range values {
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				variables[k] = s
+				continue
+			}
+			variables[k] = string(raw)
+		}
+		return variables, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid outputs line %d %q", n, line)
+		}
+		variables[strings.TrimSpace(k)] = v
+	}
+	return variables, scanner.Err()
+}
+
+// variablesEnv returns the variables of the process as sorted environment
+// variables prefixed by VariablesEnvPrefix
+func (p *Process) variablesEnv() []string {
+	var env []string
+	for k, v := range p.Variables {
+		env = append(env, VariablesEnvPrefix+envKey(k)+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// copyVariables returns a copy of the variables of the process
+func (p *Process) copyVariables() map[string]string {
+	if len(p.Variables) == 0 {
+		return nil
+	}
+	variables := make(map[string]string, len(p.Variables))
+	for k, v := range p.Variables {
+		variables[k] = v
+	}
+	return variables
+}
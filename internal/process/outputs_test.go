@@ -0,0 +1,96 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Outputs", func() {
+	Context("ParseOutputs", func() {
+		It("parses key=value lines", func() {
+			variables, err := process.ParseOutputs([]byte("# comment\nimage.digest=sha256:abc\n\nnamespace = test\nquery=a=b\n"))
+			Expect(err).To(Succeed())
+			Expect(variables).To(Equal(map[string]string{
+				"image.digest": "sha256:abc",
+				"namespace":    " test",
+				"query":        "a=b",
+			}))
+		})
+		It("parses a JSON object", func() {
+			variables, err := process.ParseOutputs([]byte(`{"namespace": "test", "replicas": 3, "tags": ["a"]}`))
+			Expect(err).To(Succeed())
+			Expect(variables).To(Equal(map[string]string{
+				"namespace": "test",
+				"replicas":  "3",
+				"tags":      `["a"]`,
+			}))
+		})
+		It("fails on invalid content", func() {
+			_, err := process.ParseOutputs([]byte("namespace"))
+			Expect(err).To(HaveOccurred())
+			_, err = process.ParseOutputs([]byte("{"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("LoopProcess", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "outputs")
+			Expect(err).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("exports the variables to the next scripts", func() {
+			first := filepath.Join(dir, "01.sh")
+			second := filepath.Join(dir, "02.sh")
+			third := filepath.Join(dir, "03.sh")
+			Expect(os.WriteFile(first, []byte("#!/bin/bash\necho image-digest=sha256:abc >> $OUTPUTS\n"), 0755)).To(Succeed())
+			Expect(os.WriteFile(second, []byte("#!/bin/bash\necho '{\"namespace\": \"test\"}' > $OUTPUTS\n"), 0755)).To(Succeed())
+			Expect(os.WriteFile(third, []byte("#!/bin/bash\necho -n $OUTPUT_IMAGE_DIGEST $OUTPUT_NAMESPACE\n"), 0755)).To(Succeed())
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			Expect(p.MainProcess(context.Background())).To(Succeed())
+			Expect(p.Outputs[2].Stdout).To(Equal("sha256:abc test"))
+			Expect(p.Variables).To(Equal(map[string]string{"image-digest": "sha256:abc", "namespace": "test"}))
+			Expect(p.GetStatus("", nil).Variables).To(Equal(p.Variables))
+		})
+		It("does not override the environment of the next scripts", func() {
+			first := filepath.Join(dir, "01.sh")
+			second := filepath.Join(dir, "02.sh")
+			Expect(os.WriteFile(first, []byte("#!/bin/bash\necho path=/nowhere >> $OUTPUTS\n"), 0755)).To(Succeed())
+			Expect(os.WriteFile(second, []byte("#!/bin/bash\necho -n $OUTPUT_PATH\nls / > /dev/null\n"), 0755)).To(Succeed())
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			Expect(p.MainProcess(context.Background())).To(Succeed())
+			Expect(p.Outputs[1].Stdout).To(Equal("/nowhere"))
+		})
+	})
+})
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sync"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/x/logx"
+)
+
+// block is a script run alone or the consecutive scripts of a parallel group
+type block struct {
+	group   *config.Group
+	scripts []config.Script
+}
+
+// blocks splits the scripts into the blocks run one after the other
+func (p *Process) blocks(scripts []string) []block {
+	var bs []block
+	pl := p.pipeline()
+	for _, script := range scripts {
+		s := pl.GetScript(script)
+		if n := len(bs); s.Parallel != nil && n > 0 && bs[n-1].group == s.Parallel {
+			bs[n-1].scripts = append(bs[n-1].scripts, s)
+			continue
+		}
+		bs = append(bs, block{group: s.Parallel, scripts: []config.Script{s}})
+	}
+	return bs
+}
+
+// runGroup runs the scripts of the block at the same time within the limit
+// of the group. The outputs are added in the order of the scripts once all
+// are over. With fail fast the first failure stops the other scripts
+func (p *Process) runGroup(ctx context.Context, b block, arg ...string) error {
+	log := logx.WithName(ctx, "Process.runGroup")
+	log.Info("run group", "group", b.group.Name, "scripts", len(b.scripts))
+	gctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	limit := b.group.MaxParallel
+	if limit <= 0 || limit > len(b.scripts) {
+		limit = len(b.scripts)
+	}
+	env := p.variablesEnv()
+	outputs := make([]Output, len(b.scripts))
+	errs := make([]error, len(b.scripts))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, s := range b.scripts {
+		wg.Add(1)
+		go func(i int, s config.Script) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if cause := context.Cause(gctx); stopped(cause) {
+				outputs[i] = Output{Name: path.Base(s.Path), Status: StatusSkipped, Log: cause.Error()}
+				errs[i] = cause
+				return
+			}
+			outputs[i], errs[i] = p.step(gctx, s, env, arg...)
+			if errs[i] != nil && !stopped(errs[i]) && b.group.FailFast {
+				log.Info("stop group", "group", b.group.Name, "script", s.Path)
+				cancel(ErrFailFast)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	for _, o := range outputs {
+		p.addOutput(o)
+	}
+	if errors.Is(context.Cause(ctx), ErrCancelled) {
+		return ErrCancelled
+	}
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrFailFast) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Parallel", func() {
+	var dir string
+	add := func(name string, content string) {
+		writeScript(dir, name, "#!/bin/bash\n"+content)
+	}
+	run := func(manifest string) (*process.Process, error) {
+		Expect(os.WriteFile(filepath.Join(dir, config.ManifestFile), []byte(manifest), 0644)).To(Succeed())
+		e := newExecutor(config.Config{MainScriptFolder: dir})
+		defer e.Close()
+		p := newProcess(e, "")
+		return p, p.MainProcess(context.Background())
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "parallel")
+		Expect(err).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("runs the scripts of the group at the same time", func() {
+		add("00-before.sh", "echo namespace=test >> $OUTPUTS\n")
+		add("01-a.sh", "sleep 0.5\necho -n a $OUTPUT_NAMESPACE\n")
+		add("02-b.sh", "sleep 0.5\necho -n b\n")
+		add("03-c.sh", "echo -n c\necho c=done >> $OUTPUTS\n")
+		add("04-after.sh", "echo -n $OUTPUT_C\n")
+		start := time.Now()
+		p, err := run(`groups:
+  - name: charts
+scripts:
+  - name: 00-before.sh
+  - name: 01-a.sh
+    group: charts
+  - name: 02-b.sh
+    group: charts
+  - name: 03-c.sh
+    group: charts
+`)
+		Expect(err).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", 900*time.Millisecond))
+		Expect(p.Outputs).To(HaveLen(5))
+		Expect(p.Outputs[1].Stdout).To(Equal("a test"))
+		Expect(p.Outputs[2].Stdout).To(Equal("b"))
+		Expect(p.Outputs[3].Stdout).To(Equal("c"))
+		Expect(p.Outputs[4].Stdout).To(Equal("done"))
+	})
+	It("limits the scripts run at the same time", func() {
+		lock := filepath.Join(dir, "lock")
+		for _, name := range []string{"a.sh", "b.sh", "c.sh"} {
+			add(name, "mkdir "+lock+" || exit 1\nsleep 0.1\nrmdir "+lock+"\n")
+		}
+		p, err := run(`groups:
+  - name: charts
+    max_parallel: 1
+scripts:
+  - name: a.sh
+    group: charts
+  - name: b.sh
+    group: charts
+  - name: c.sh
+    group: charts
+`)
+		Expect(err).To(Succeed())
+		Expect(p.Outputs).To(HaveLen(3))
+	})
+	It("waits for all the scripts on failure", func() {
+		add("a.sh", "exit 1\n")
+		add("b.sh", "sleep 0.2\n")
+		p, err := run(`groups:
+  - name: charts
+scripts:
+  - name: a.sh
+    group: charts
+  - name: b.sh
+    group: charts
+`)
+		Expect(err).ToNot(Succeed())
+		Expect(p.Outputs).To(HaveLen(2))
+		Expect(p.Outputs[0].Status).To(Equal("failed"))
+		Expect(p.Outputs[1].Status).To(Equal("succeeded"))
+	})
+	It("stops the other scripts on failure with fail fast", func() {
+		add("a.sh", "sleep 0.1\nexit 3\n")
+		add("b.sh", "sleep 5\n")
+		add("c.sh", "exit 0\n")
+		start := time.Now()
+		p, err := run(`groups:
+  - name: charts
+    fail_fast: true
+    max_parallel: 2
+scripts:
+  - name: a.sh
+    group: charts
+  - name: b.sh
+    group: charts
+  - name: c.sh
+    group: charts
+`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("exit status 3"))
+		Expect(time.Since(start)).To(BeNumerically("<", 3*time.Second))
+		Expect(p.Outputs).To(HaveLen(3))
+		Expect(p.Outputs[0].Status).To(Equal("failed"))
+		Expect(p.Outputs[1].Status).To(Equal(process.StatusCancelled))
+		Expect(p.Outputs[2].Status).To(Or(Equal(process.StatusSkipped), Equal("succeeded")))
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+const (
+	// PayloadJSONFileEnv holds the path of the JSON payload file
+	PayloadJSONFileEnv = "PAYLOAD_JSON_FILE"
+)
+
+// SetPayload delivers the JSON payload to the scripts according to the
+// configured modes. The JSON file is written next to the YAML file
+func (p *Process) SetPayload(payload []byte, filename string) error {
+	p.Payload = payload
+	pl := p.pipeline()
+	if pl == nil {
+		return fmt.Errorf("%w: %s", ErrPipelineNotFound, p.Pipeline)
+	}
+	if pl.HasPayloadMode(config.PayloadModeEnv) {
+		env, err := Flatten(p.snapshot().GetPayloadEnvPrefix(), payload)
+		if err != nil {
+			return err
+		}
+		p.Env = append(p.Env, env...)
+	}
+	if pl.HasPayloadMode(config.PayloadModeStdin) {
+		p.Stdin = payload
+	}
+	if pl.HasPayloadMode(config.PayloadModeJSON) {
+		name := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".json"
+		if err := os.WriteFile(name, payload, 0600); err != nil {
+			return err
+		}
+		p.Env = append(p.Env, PayloadJSONFileEnv+"="+name)
+	}
+	return nil
+}
+
+// Flatten returns the leaves of the JSON payload as sorted environment
+// variables. The keys are upper cased and joined with underscores, the
+// array items are keyed by their index
+func Flatten(prefix string, payload []byte) ([]string, error) {
+	var value interface{}
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	if err := d.Decode(&value); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	var env []string
+	flatten(strings.TrimSuffix(prefix, "_"), value, &env)
+	sort.Strings(env)
+	return env, nil
+}
+
+func flatten(key string, value interface{}, env *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flatten(join(key, envKey(k)), item, env)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flatten(join(key, strconv.Itoa(i)), item, env)
+		}
+	case nil:
+		*env = append(*env, key+"=")
+	default:
+		*env = append(*env, fmt.Sprintf("%s=%v", key, v))
+	}
+}
+
+func join(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "_" + name
+}
+
+// envKey returns the key upper cased with the characters not allowed in an
+// environment variable name replaced by underscores
+func envKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Payload", func() {
+	Context("Flatten", func() {
+		It("flattens the leaves of the payload", func() {
+			env, err := process.Flatten("PAYLOAD_", []byte(`{
+  "global": {"label": "test", "replica-count": 3},
+  "images": [{"tag": "1.0"}, {"tag": "2.0"}],
+  "enabled": true,
+  "empty": null
+}`))
+			Expect(err).To(Succeed())
+			Expect(env).To(Equal([]string{
+				"PAYLOAD_EMPTY=",
+				"PAYLOAD_ENABLED=true",
+				"PAYLOAD_GLOBAL_LABEL=test",
+				"PAYLOAD_GLOBAL_REPLICA_COUNT=3",
+				"PAYLOAD_IMAGES_0_TAG=1.0",
+				"PAYLOAD_IMAGES_1_TAG=2.0",
+			}))
+		})
+		It("keeps large numbers as is", func() {
+			env, err := process.Flatten("", []byte(`{"id": 12345678901234567890}`))
+			Expect(err).To(Succeed())
+			Expect(env).To(Equal([]string{"ID=12345678901234567890"}))
+		})
+		It("fails on invalid payload", func() {
+			_, err := process.Flatten("PAYLOAD_", []byte(`{`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("SetPayload", func() {
+		var (
+			dir     string
+			scripts string
+		)
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "payload")
+			Expect(err).To(Succeed())
+			scripts = filepath.Join(dir, "scripts")
+			writeScript(scripts, "payload.sh", "#!/bin/bash\necho $PAYLOAD_NAME\ncat\necho\ncat $PAYLOAD_JSON_FILE\n")
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("only keeps the file by default", func() {
+			e := newExecutor(config.Config{MainScriptFolder: scripts})
+			defer e.Close()
+			p := newProcess(e, "payload")
+			Expect(p.SetPayload([]byte(`{"name":"test"}`), filepath.Join(dir, "values.yaml"))).To(Succeed())
+			Expect(p.Env).To(BeEmpty())
+			Expect(p.Stdin).To(BeNil())
+			Expect(filepath.Join(dir, "values.json")).ToNot(BeAnExistingFile())
+		})
+		It("delivers the payload to the scripts", func() {
+			e := newExecutor(config.Config{
+				MainScriptFolder: scripts,
+				PayloadModes:     []string{config.PayloadModeEnv, config.PayloadModeStdin, config.PayloadModeJSON},
+			})
+			defer e.Close()
+			p := newProcess(e, "payload")
+			Expect(p.SetPayload([]byte(`{"name":"test"}`), filepath.Join(dir, "values.yaml"))).To(Succeed())
+			Expect(p.MainProcess(context.Background())).To(Succeed())
+			Expect(p.Outputs).To(HaveLen(1))
+			Expect(p.Outputs[0].Stdout).To(Equal("test\n{\"name\":\"test\"}\n{\"name\":\"test\"}"))
+		})
+	})
+})
@@ -0,0 +1,247 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+	"sync"
+
+	"github.com/w6d-io/x/logx"
+)
+
+// Pool runs the processes with a bounded concurrency and queues the others
+// in FIFO order. Processes sharing a lock key are run one at a time and the
+// processes of a pipeline up to its concurrency
+type Pool struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	queue     []*task
+	locked    map[string]bool
+	pipelines map[string]int
+	size      int
+	depth     int
+	workers   int
+	running   int
+	closed    bool
+}
+
+type task struct {
+	ctx  context.Context
+	p    *Process
+	arg  []string
+	done chan error
+}
+
+// NewPool returns a pool running at most concurrency processes and queuing at
+// most depth others
+func NewPool(concurrency, depth int) *Pool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if depth < 0 {
+		depth = 0
+	}
+	pl := &Pool{
+		size:      concurrency,
+		depth:     depth,
+		workers:   concurrency,
+		locked:    make(map[string]bool),
+		pipelines: make(map[string]int),
+	}
+	pl.cond = sync.NewCond(&pl.mu)
+	for i := 0; i < concurrency; i++ {
+		go pl.work()
+	}
+	return pl
+}
+
+// Resize changes the concurrency and the depth of the pool. The workers over
+// the new concurrency stop once their running process is over and the queued
+// processes are kept even over the new depth
+func (pl *Pool) Resize(concurrency, depth int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if depth < 0 {
+		depth = 0
+	}
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if pl.closed || (pl.size == concurrency && pl.depth == depth) {
+		return
+	}
+	log := logx.WithName(nil, "Pool.Resize")
+	log.Info("resize pool", "concurrency", concurrency, "depth", depth)
+	pl.size = concurrency
+	pl.depth = depth
+	for ; pl.workers < concurrency; pl.workers++ {
+		go pl.work()
+	}
+	pl.cond.Broadcast()
+}
+
+// Submit queues the process. The returned channel receives the result of the
+// execution. It fails with ErrQueueFull when the queue is full. When the
+// process supersedes, the queued processes with the same lock key are cancelled
+func (pl *Pool) Submit(ctx context.Context, p *Process, arg ...string) (<-chan error, error) {
+	log := logx.WithName(ctx, "Pool.Submit")
+	pl.mu.Lock()
+	if pl.closed {
+		pl.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	supersede := p.Supersede && p.LockKey != ""
+	queued := len(pl.queue)
+	if supersede {
+		queued -= pl.countKey(p.LockKey)
+	}
+	if pl.running+queued >= pl.size+pl.depth {
+		pl.mu.Unlock()
+		log.Info("queue is full", "id", p.ID, "depth", pl.depth)
+		return nil, ErrQueueFull
+	}
+	var superseded []*task
+	if supersede {
+		superseded = pl.removeKey(p.LockKey)
+	}
+	p.setState(StateQueued, nil)
+	t := &task{ctx: ctx, p: p, arg: arg, done: make(chan error, 1)}
+	pl.queue = append(pl.queue, t)
+	pl.cond.Broadcast()
+	pl.mu.Unlock()
+	for _, s := range superseded {
+		log.Info("supersede queued job", "id", s.p.ID, "by", p.ID, "lock_key", p.LockKey)
+		s.done <- s.p.Cancelled(s.ctx, s.arg...)
+	}
+	return t.done, nil
+}
+
+// Position returns the 1-based position of the job in the queue, 0 when the
+// job is not queued
+func (pl *Pool) Position(id string) int {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for i, t := range pl.queue {
+		if t.p.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Close stops the workers once their running process is over. The queued
+// processes are cancelled
+func (pl *Pool) Close() {
+	pl.mu.Lock()
+	pl.closed = true
+	queue := pl.queue
+	pl.queue = nil
+	pl.cond.Broadcast()
+	pl.mu.Unlock()
+	for _, t := range queue {
+		log := logx.WithName(t.ctx, "Pool.Close")
+		log.Info("cancel queued job", "id", t.p.ID)
+		t.done <- t.p.Cancelled(t.ctx, t.arg...)
+	}
+}
+
+func (pl *Pool) work() {
+	for {
+		t := pl.next()
+		if t == nil {
+			return
+		}
+		t.done <- t.p.Execute(t.ctx, t.arg...)
+		pl.mu.Lock()
+		pl.running--
+		pl.pipelines[t.p.Pipeline]--
+		if t.p.LockKey != "" {
+			delete(pl.locked, t.p.LockKey)
+		}
+		pl.cond.Broadcast()
+		pl.mu.Unlock()
+	}
+}
+
+// next waits for the first queued task whose lock key is free and whose
+// pipeline is under its concurrency
+func (pl *Pool) next() *task {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for {
+		if pl.closed || pl.workers > pl.size {
+			pl.workers--
+			return nil
+		}
+		for i, t := range pl.queue {
+			if t.p.LockKey != "" && pl.locked[t.p.LockKey] {
+				continue
+			}
+			if t.p.Concurrency > 0 && pl.pipelines[t.p.Pipeline] >= t.p.Concurrency {
+				continue
+			}
+			pl.queue = append(pl.queue[:i], pl.queue[i+1:]...)
+			if t.p.LockKey != "" {
+				pl.locked[t.p.LockKey] = true
+			}
+			pl.running++
+			pl.pipelines[t.p.Pipeline]++
+			return t
+		}
+		pl.cond.Wait()
+	}
+}
+
+// countKey returns the number of queued tasks with the lock key
+func (pl *Pool) countKey(key string) int {
+	count := 0
+	for _, t := range pl.queue {
+		if t.p.LockKey == key {
+			count++
+		}
+	}
+	return count
+}
+
+// removeKey takes the tasks with the lock key out of the queue
+func (pl *Pool) removeKey(key string) []*task {
+	var removed []*task
+	queue := pl.queue[:0]
+	for _, t := range pl.queue {
+		if t.p.LockKey == key {
+			removed = append(removed, t)
+			continue
+		}
+		queue = append(queue, t)
+	}
+	pl.queue = queue
+	return removed
+}
+
+// remove takes the job out of the queue
+func (pl *Pool) remove(id string) *task {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for i, t := range pl.queue {
+		if t.p.ID == id {
+			pl.queue = append(pl.queue[:i], pl.queue[i+1:]...)
+			return t
+		}
+	}
+	return nil
+}
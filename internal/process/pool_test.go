@@ -0,0 +1,228 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Pool", func() {
+	var (
+		dir      string
+		err      error
+		executor *process.Executor
+		reload   func(config.Config)
+	)
+	// sized returns an executor running concurrency jobs and queuing depth
+	sized := func(concurrency, depth int, pipelines ...config.Pipeline) *process.Executor {
+		var source config.Source
+		source, reload = newSource(config.Config{
+			MainScriptFolder: dir,
+			Concurrency:      concurrency,
+			QueueSize:        depth,
+			Pipelines:        pipelines,
+		})
+		return process.NewExecutor(source, process.NewMemoryRegistry())
+	}
+	submitTo := func(e *process.Executor, id, name string) (<-chan error, error) {
+		Expect(e.Register(id)).To(Succeed())
+		p, err := e.New(id, name)
+		Expect(err).To(Succeed())
+		return e.Submit(p)
+	}
+	submit := func(id string) (<-chan error, error) {
+		return submitTo(executor, id, "")
+	}
+	state := func(id string) process.State {
+		job, _ := executor.GetJob(id)
+		return job.State
+	}
+	BeforeEach(func() {
+		dir, err = os.MkdirTemp("", "pool_dir")
+		Expect(err).To(Succeed())
+		writeScript(dir, "sleep.sh", "#!/bin/bash\nsleep 0.5\n")
+		executor = sized(1, 1)
+	})
+	AfterEach(func() {
+		executor.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("queues the jobs over the concurrency and rejects them over the depth", func() {
+		first, err := submit("first")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+		second, err := submit("second")
+		Expect(err).To(Succeed())
+		job, _ := executor.GetJob("second")
+		Expect(job.Position).To(Equal(1))
+		_, err = submit("third")
+		Expect(err).To(MatchError(process.ErrQueueFull))
+		Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+		Eventually(second, 5*time.Second).Should(Receive(BeNil()))
+		job, _ = executor.GetJob("second")
+		Expect(job.State).To(Equal(process.StateSucceeded))
+		Expect(job.Position).To(Equal(0))
+	})
+	It("cancels a queued job", func() {
+		_, err := submit("first")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+		second, err := submit("second")
+		Expect(err).To(Succeed())
+		Expect(executor.Cancel("second")).To(Succeed())
+		Eventually(second).Should(Receive(HaveOccurred()))
+		job, _ := executor.GetJob("second")
+		Expect(job.State).To(Equal(process.StateCancelled))
+		Expect(job.Position).To(Equal(0))
+	})
+	Context("lock key", func() {
+		submitKey := func(id, key string, supersede bool) (<-chan error, error) {
+			Expect(executor.Register(id)).To(Succeed())
+			p, err := executor.New(id, "")
+			Expect(err).To(Succeed())
+			p.LockKey = key
+			p.Supersede = supersede
+			return executor.Submit(p)
+		}
+		BeforeEach(func() {
+			executor.Close()
+			executor = sized(2, 5)
+		})
+		It("serializes the jobs sharing a key", func() {
+			first, err := submitKey("first", "release", false)
+			Expect(err).To(Succeed())
+			second, err := submitKey("second", "release", false)
+			Expect(err).To(Succeed())
+			other, err := submitKey("other", "another", false)
+			Expect(err).To(Succeed())
+			Eventually(func() process.State { return state("other") }, time.Second).Should(Equal(process.StateMain))
+			Expect(state("first")).To(Equal(process.StateMain))
+			Expect(state("second")).To(Equal(process.StateQueued))
+			job, _ := executor.GetJob("second")
+			Expect(job.LockKey).To(Equal("release"))
+			Expect(job.Position).To(Equal(1))
+			Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+			Eventually(other, 5*time.Second).Should(Receive(BeNil()))
+			Eventually(second, 5*time.Second).Should(Receive(BeNil()))
+		})
+		It("supersedes the queued job sharing a key", func() {
+			first, err := submitKey("first", "release", false)
+			Expect(err).To(Succeed())
+			Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+			second, err := submitKey("second", "release", false)
+			Expect(err).To(Succeed())
+			third, err := submitKey("third", "release", true)
+			Expect(err).To(Succeed())
+			Eventually(second, time.Second).Should(Receive(HaveOccurred()))
+			Expect(state("second")).To(Equal(process.StateCancelled))
+			Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+			Eventually(third, 5*time.Second).Should(Receive(BeNil()))
+		})
+	})
+	It("bounds the running jobs of a pipeline", func() {
+		executor.Close()
+		executor = sized(3, 5, config.Pipeline{Name: "sleep", MainScriptFolder: dir, Concurrency: 1})
+		var done []<-chan error
+		for _, id := range []string{"first", "second", "other"} {
+			name := "sleep"
+			if id == "other" {
+				name = ""
+			}
+			d, err := submitTo(executor, id, name)
+			Expect(err).To(Succeed())
+			done = append(done, d)
+		}
+		Eventually(func() process.State { return state("other") }, time.Second).Should(Equal(process.StateMain))
+		Expect(state("first")).To(Equal(process.StateMain))
+		Expect(state("second")).To(Equal(process.StateQueued))
+		job, _ := executor.GetJob("second")
+		Expect(job.Pipeline).To(Equal("sleep"))
+		for _, d := range done {
+			Eventually(d, 5*time.Second).Should(Receive(BeNil()))
+		}
+	})
+	It("cancels the queued jobs of a removed pipeline", func() {
+		executor.Close()
+		executor = sized(1, 5, config.Pipeline{Name: "sleep", MainScriptFolder: dir})
+		first, err := submit("first")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+		second, err := submitTo(executor, "second", "sleep")
+		Expect(err).To(Succeed())
+		third, err := submitTo(executor, "third", "sleep")
+		Expect(err).To(Succeed())
+		reload(config.Config{MainScriptFolder: dir, Concurrency: 1, QueueSize: 5})
+		Expect(executor.Cancel("second")).To(Succeed())
+		Eventually(second).Should(Receive(MatchError(process.ErrCancelled)))
+		Expect(state("second")).To(Equal(process.StateCancelled))
+		executor.Close()
+		Eventually(third).Should(Receive(MatchError(process.ErrCancelled)))
+		Expect(state("third")).To(Equal(process.StateCancelled))
+		Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+	})
+	It("grows with the reloaded concurrency", func() {
+		first, err := submit("first")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+		reload(config.Config{MainScriptFolder: dir, Concurrency: 2, QueueSize: 1})
+		second, err := submit("second")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("second") }, time.Second).Should(Equal(process.StateMain))
+		Expect(state("first")).To(Equal(process.StateMain))
+		Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+		Eventually(second, 5*time.Second).Should(Receive(BeNil()))
+	})
+	It("shrinks with the reloaded concurrency and queue size", func() {
+		executor.Close()
+		executor = sized(2, 5)
+		reload(config.Config{MainScriptFolder: dir, Concurrency: 1, QueueSize: 1})
+		first, err := submit("first")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+		second, err := submit("second")
+		Expect(err).To(Succeed())
+		Consistently(func() process.State { return state("second") }, 200*time.Millisecond).Should(Equal(process.StateQueued))
+		_, err = submit("third")
+		Expect(err).To(MatchError(process.ErrQueueFull))
+		Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+		Eventually(second, 5*time.Second).Should(Receive(BeNil()))
+	})
+	It("cancels the queued jobs on close", func() {
+		first, err := submit("first")
+		Expect(err).To(Succeed())
+		Eventually(func() process.State { return state("first") }, time.Second).Should(Equal(process.StateMain))
+		second, err := submit("second")
+		Expect(err).To(Succeed())
+		executor.Close()
+		Eventually(second).Should(Receive(MatchError(process.ErrCancelled)))
+		Expect(state("second")).To(Equal(process.StateCancelled))
+		Eventually(first, 5*time.Second).Should(Receive(BeNil()))
+	})
+	It("refuses jobs once closed", func() {
+		executor.Close()
+		_, err := submit("closed")
+		Expect(err).To(MatchError(process.ErrPoolClosed))
+	})
+})
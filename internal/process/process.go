@@ -18,131 +18,433 @@ Created on 20/03/2021
 package process
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path"
 	"strings"
+	"syscall"
+	"time"
 
-	"github.com/w6d-io/hook"
 	"github.com/w6d-io/process-rest/internal/config"
 	"github.com/w6d-io/x/logx"
 )
 
-func Run(name string, arg ...string) (string, error) {
-	log := logx.WithName(nil, "Process.Run")
+// Run executes the command in its own process group. When the context is done
+// the group receives SIGTERM then SIGKILL once the grace period is over. The
+// outputs are captured in the result and also copied to stdout and stderr
+func Run(ctx context.Context, stdout, stderr io.Writer, name string, arg ...string) (Result, error) {
+	return RunWith(ctx, Options{Stdout: stdout, Stderr: stderr}, name, arg...)
+}
+
+// RunWith executes the command like Run with the standard input and the
+// extra environment variables of the options
+func RunWith(ctx context.Context, opts Options, name string, arg ...string) (Result, error) {
+	log := logx.WithName(ctx, "Process.Run")
 	log.V(1).Info("build command")
-	cmd := exec.Command(name, arg...)
+	cmd := exec.CommandContext(ctx, name, arg...)
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	cmd.Stdin = opts.Stdin
+	cmd.Dir = opts.Dir
+	stdout, stderr := opts.Stdout, opts.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var kill *time.Timer
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		kill = time.AfterFunc(GracePeriod, func() {
+			_ = syscall.Kill(pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = GracePeriod + WaitDelay
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+	if stdout != nil {
+		cmd.Stdout = io.MultiWriter(&outBuf, stdout)
+	}
+	if stderr != nil {
+		cmd.Stderr = io.MultiWriter(&errBuf, stderr)
+	}
 
 	log.V(1).Info("exec command and get output", "script", cmd.String())
-	output, err := cmd.Output()
+	result := Result{StartedAt: time.Now(), ExitCode: -1}
+	err := cmd.Run()
+	result.Duration = time.Since(result.StartedAt)
+	if kill != nil {
+		kill.Stop()
+	}
+	result.Stdout, result.Stderr = outBuf.String(), errBuf.String()
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			log.Error(err, "script failed", "script", cmd.String(), "stdout", string(output), "exit_code", exitErr.ExitCode(), "stderr", string(exitErr.Stderr))
-			return string(exitErr.Stderr), exitErr
+		log.Error(err, "script failed", "script", cmd.String(), "stdout", result.Stdout, "exit_code", result.ExitCode, "stderr", result.Stderr)
+		return result, err
+	}
+	log.V(1).Info("script succeeded", "output", result.Stdout)
+	return result, nil
+}
+
+// LoopProcess runs the scripts in order with their run options, the
+// consecutive scripts of a parallel group at the same time. It stops at the
+// first failure not allowed
+func (p *Process) LoopProcess(ctx context.Context, scripts []string, arg ...string) error {
+	for _, b := range p.blocks(scripts) {
+		if errors.Is(context.Cause(ctx), ErrCancelled) {
+			return ErrCancelled
 		}
-		log.Error(err, "script failed", "script", cmd.String(), "output", string(output))
-		return string(output), err
-	}
-	log.V(1).Info("script succeeded", "output", string(output))
-	return string(output), nil
-}
-
-func (p *Process) LoopProcess(scripts []string, arg ...string) error {
-	log := logx.WithName(nil, "Process.LoopProcess")
-	for _, script := range scripts {
-		log.Info("run", "script", script)
-		arg = append([]string{script}, arg...)
-		args := strings.Join(arg, " ")
-		output, err := Run("bash", "-c", args)
-		o := Output{
-			Name:   path.Base(script),
-			Status: "succeeded",
-			Log:    output,
-			Error:  "",
+		if b.group != nil {
+			if err := p.runGroup(ctx, b, arg...); err != nil {
+				return err
+			}
+			continue
 		}
+		o, err := p.step(ctx, b.scripts[0], p.variablesEnv(), arg...)
+		p.addOutput(o)
 		if err != nil {
-			log.Error(err, "process failed", "script", script)
-			o.Status = "failed"
-			o.Error = err.Error()
-			p.Outputs = append(p.Outputs, o)
 			return err
 		}
-		p.Outputs = append(p.Outputs, o)
 	}
 	return nil
 }
 
-func (p *Process) PreProcess(arg ...string) error {
-	log := logx.WithName(nil, "Process.PreProcess")
+// step runs the script unless it is skipped. The error of an allowed failure
+// is dropped
+func (p *Process) step(ctx context.Context, s config.Script, env []string, arg ...string) (Output, error) {
+	log := logx.WithName(ctx, "Process.step")
+	if reason := p.skipReason(s); reason != "" {
+		log.Info("skip", "script", s.Path, "reason", reason)
+		return Output{Name: path.Base(s.Path), Status: StatusSkipped, Log: reason}, nil
+	}
+	log.Info("run", "script", s.Path)
+	p.markRun(s.Path)
+	name, args, err := Command(p.snapshot().GetInterpreter(), s.Path, arg...)
+	if err != nil {
+		return Output{Name: path.Base(s.Path), Status: "failed", Error: err.Error()}, err
+	}
+	o, err := p.runScript(ctx, s, env, name, args...)
+	if err != nil && s.AllowFailure && !stopped(err) {
+		log.Info("failure allowed", "script", s.Path)
+		o.AllowedFailure = true
+		err = nil
+	}
+	return o, err
+}
+
+// addOutput appends the output of a script, merges its variables and records
+// the process
+func (p *Process) addOutput(o Output) {
+	p.Outputs = append(p.Outputs, o)
+	if len(o.Variables) != 0 && p.Variables == nil {
+		p.Variables = make(map[string]string)
+	}
+	for k, v := range o.Variables {
+		p.Variables[k] = v
+	}
+	p.record(nil)
+}
+
+// stopped returns whether the script was stopped by a cancellation
+func stopped(err error) bool {
+	return errors.Is(err, ErrCancelled) || errors.Is(err, ErrFailFast)
+}
+
+// runScript runs the script until it succeeds or its retries are exhausted,
+// waiting for the backoff doubled on each retry
+func (p *Process) runScript(ctx context.Context, s config.Script, env []string, name string, args ...string) (Output, error) {
+	log := logx.WithName(ctx, "Process.runScript")
+	backoff := s.Backoff
+	for attempt := 1; ; attempt++ {
+		o, err := p.runOnce(ctx, s, env, name, args...)
+		o.Attempts = attempt
+		if err == nil || stopped(err) || attempt > s.Retries {
+			return o, err
+		}
+		log.Info("retry", "script", s.Path, "attempt", attempt, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			if cause := context.Cause(ctx); stopped(cause) {
+				o.Status = StatusCancelled
+				err = fmt.Errorf("script %s %w", o.Name, cause)
+				o.Error = err.Error()
+			}
+			return o, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runOnce runs the script and returns its output
+func (p *Process) runOnce(ctx context.Context, s config.Script, env []string, name string, args ...string) (Output, error) {
+	log := logx.WithName(ctx, "Process.runOnce")
+	o := Output{Name: path.Base(s.Path), Status: "failed"}
+	outputs, err := p.createOutputs()
+	if err != nil {
+		o.Error = err.Error()
+		return o, err
+	}
+	env = append(append(append([]string{}, p.Env...), env...), OutputsEnv+"="+outputs)
+	sctx, cancel := p.scriptContext(ctx, s.Timeout)
+	stdout, stderr := p.writers(o.Name)
+	opts := Options{Stdout: stdout, Stderr: stderr, Dir: p.Workspace, Env: env}
+	if p.Stdin != nil {
+		opts.Stdin = bytes.NewReader(p.Stdin)
+	}
+	result, err := RunWith(sctx, opts, name, args...)
+	stdout.Flush()
+	stderr.Flush()
+	variables, oerr := readOutputs(outputs)
+	if oerr != nil {
+		log.Error(oerr, "read outputs failed", "script", s.Path)
+	}
+	cause := context.Cause(sctx)
+	timedOut := errors.Is(sctx.Err(), context.DeadlineExceeded)
+	cancel()
+	o = Output{
+		Name:      o.Name,
+		Status:    "succeeded",
+		Log:       result.Stdout,
+		Error:     "",
+		Stdout:    result.Stdout,
+		Stderr:    result.Stderr,
+		ExitCode:  result.ExitCode,
+		StartedAt: result.StartedAt,
+		Duration:  result.Duration,
+		Variables: variables,
+	}
+	if err != nil {
+		log.Error(err, "process failed", "script", s.Path)
+		o.Status = "failed"
+		if _, ok := err.(*exec.ExitError); ok {
+			o.Log = result.Stderr
+		}
+		switch {
+		case stopped(cause):
+			o.Status = StatusCancelled
+			err = fmt.Errorf("script %s %w", o.Name, cause)
+		case timedOut:
+			o.Status = StatusTimedOut
+			err = &TimeoutError{Script: o.Name, Cause: err}
+		}
+		o.Error = err.Error()
+		return o, err
+	}
+	return o, nil
+}
+
+// Command returns the command and the arguments running the script with the
+// interpreter
+func Command(name, script string, arg ...string) (string, []string, error) {
+	interpreter, err := config.GetInterpreterCommand(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if interpreter == "" {
+		return script, arg, nil
+	}
+	return interpreter, append([]string{script}, arg...), nil
+}
+
+// scriptContext returns the context bound to the script timeout, the one of
+// the process when not set
+func (p *Process) scriptContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = p.ScriptTimeout
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (p *Process) PreProcess(ctx context.Context, arg ...string) error {
+	log := logx.WithName(ctx, "Process.PreProcess")
 	log.V(1).Info("loop process")
-	return p.LoopProcess(config.GetPreScript(), arg...)
+	return p.LoopProcess(ctx, p.pipeline().GetPreScript(), arg...)
 }
 
-func (p *Process) PostProcess(arg ...string) error {
-	log := logx.WithName(nil, "Process.PostProcess")
+func (p *Process) PostProcess(ctx context.Context, arg ...string) error {
+	log := logx.WithName(ctx, "Process.PostProcess")
 	log.V(1).Info("loop process")
-	return p.LoopProcess(config.GetPostScript(), arg...)
+	return p.LoopProcess(ctx, p.pipeline().GetPostScript(), arg...)
 }
 
-func (p *Process) MainProcess(arg ...string) error {
-	log := logx.WithName(nil, "Process.MainProcess")
+func (p *Process) MainProcess(ctx context.Context, arg ...string) error {
+	log := logx.WithName(ctx, "Process.MainProcess")
+	if steps := p.pipeline().GetSteps(); len(steps) != 0 {
+		log.V(1).Info("run steps")
+		return p.RunSteps(ctx, steps, arg...)
+	}
 	log.V(1).Info("loop process")
-	return p.LoopProcess(config.GetMainScript(), arg...)
+	return p.LoopProcess(ctx, p.pipeline().GetMainScript(), arg...)
 }
 
-func Execute(id string, arg ...string) {
-	log := logx.WithName(nil, "Process.Execute")
+// pipeline returns the configuration of the pipeline run by the process, the
+// one it started with once executed. It is nil when the pipeline was removed
+// from the configuration before the process started
+func (p *Process) pipeline() *config.Pipeline {
+	if p.config != nil {
+		return p.config
+	}
+	pl, _ := p.source().GetPipeline(p.Pipeline)
+	return pl
+}
+
+// GetPipeline returns the configuration of the pipeline run by the process
+func (p *Process) GetPipeline() *config.Pipeline {
+	return p.pipeline()
+}
+
+// snapshot returns the configuration the process is run with
+func (p *Process) snapshot() *config.Snapshot {
+	if pl := p.pipeline(); pl != nil {
+		return pl.GetSnapshot()
+	}
+	return p.source()
+}
+
+// Execute runs the pre, main and post scripts and returns an *Error holding
+// the failing stage code on failure
+func (p *Process) Execute(ctx context.Context, arg ...string) error {
+	log := logx.WithName(ctx, "Process.Execute")
 	log.V(1).Info("loop process")
-	p := new(Process)
+	id := p.ID
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	ctx, release := p.track(ctx)
+	defer release()
+	// keep the scripts of the active configuration whatever the reloads
+	p.config = p.pipeline()
+	if p.config == nil {
+		err := fmt.Errorf("%w: %s", ErrPipelineNotFound, p.Pipeline)
+		log.Error(err, "pipeline removed before the job started")
+		p.setState(StateFailed, err)
+		return err
+	}
+	log.V(1).Info("run", "pipeline", p.Pipeline, "revision", p.snapshot().Revision)
+	fail := func(stage string, code int, err error) error {
+		if errors.Is(err, ErrCancelled) {
+			return p.Cancelled(ctx, arg...)
+		}
+		log.Error(err, stage+" process failed")
+		e := NewError(err, code, stage+" process failed")
+		if ferr := p.Finally(ctx, e, arg...); ferr != nil {
+			log.Error(ferr, "finally process failed")
+		}
+		p.setState(StateFailed, err)
+		p.Notify(id, scope(stage, err), e)
+		return e
+	}
 	errc := make(chan error)
 	go func() {
 		// do pre-process
-		if err := p.PreProcess(arg...); err != nil {
-			log.Error(err, "pre process failed")
-			p.Notify(id, "pre-process-failed", err)
-			errc <- NewError(err, 550, "pre process failed")
+		p.setState(StatePre, nil)
+		if err := p.PreProcess(ctx, arg...); err != nil {
+			errc <- fail("pre", CodePreProcess, err)
 			return
 		}
 
 		// do main process
-		if err := p.MainProcess(arg...); err != nil {
-			log.Error(err, "main process failed")
-			p.Notify(id, "main-process-failed", err)
-			errc <- NewError(err, 551, "main process failed")
+		p.setState(StateMain, nil)
+		if err := p.MainProcess(ctx, arg...); err != nil {
+			errc <- fail("main", CodeMainProcess, err)
 			return
 		}
 
 		// do post-process
-		if err := p.PostProcess(arg...); err != nil {
-			log.Error(err, "post process failed")
-			p.Notify(id, "post-process-failed", err)
-			errc <- NewError(err, 552, "post process failed")
+		p.setState(StatePost, nil)
+		if err := p.PostProcess(ctx, arg...); err != nil {
+			errc <- fail("post", CodePostProcess, err)
+			return
+		}
+
+		// do finally-process
+		if err := p.Finally(ctx, nil, arg...); err != nil {
+			log.Error(err, "finally process failed")
+			p.setState(StateFailed, err)
+			e := NewError(err, CodeFinallyProcess, "finally process failed")
+			p.Notify(id, scope("finally", err), e)
+			errc <- e
 			return
 		}
+		p.setState(StateSucceeded, nil)
 		p.Notify(id, "process-succeeded", nil)
 		errc <- nil
 	}()
 
-	//for range []string{"1", "2"} {
 	if err := <-errc; err != nil {
 		log.Error(err, "process failed")
+		return err
+	}
+	return nil
+}
+
+// scope returns the hook scope of the stage failure
+func scope(stage string, err error) string {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return stage + "-process-timed-out"
 	}
-	//}
+	return stage + "-process-failed"
 }
 
+// Notify sends the status of the process to the hooks matching the scope. The
+// scope of a named pipeline is prefixed with its name, even once removed from
+// the configuration
 func (p *Process) Notify(id string, scope string, err error) {
 	log := logx.WithName(nil, "Process.Notify")
+	pl := p.pipeline()
+	if pl == nil {
+		pl = &config.Pipeline{Name: p.Pipeline}
+	}
+	scope = pl.Scope(scope)
 
+	log.V(1).Info("send", "scope", scope)
+	config.Send(context.Background(), p.GetStatus(id, err), scope)
+}
+
+// GetStatus returns the hook payload of the process. The legacy log message
+// is only set when enabled in the configuration
+func (p *Process) GetStatus(id string, err error) *Status {
+	var timeoutErr *TimeoutError
 	status := &Status{
-		Success: err == nil,
-		Log:     p.GetLogMessage(err),
-		ID:      id,
+		Version:   StatusVersion,
+		ID:        id,
+		Pipeline:  p.Pipeline,
+		Success:   err == nil,
+		TimedOut:  errors.As(err, &timeoutErr),
+		Cancelled: errors.Is(err, ErrCancelled),
+		Outputs:   append([]Output{}, p.Outputs...),
+		Variables: p.copyVariables(),
 	}
-	log.V(1).Info("send", "scope", scope)
-	_ = hook.Send(context.Background(), status, scope)
+	cause := err
+	var e *Error
+	if errors.As(err, &e) {
+		status.Stage = e.GetStage()
+		cause = e.Cause
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	if p.snapshot().IsHookLegacyLog() {
+		status.Log = p.GetLogMessage(cause)
+	}
+	return status
 }
 
+// GetLogMessage returns the legacy log message of the hook payload
+//
+// Deprecated: the message is not valid JSON, use the Status outputs
 func (p *Process) GetLogMessage(err error) string {
 	var messages []string
 	if err != nil {
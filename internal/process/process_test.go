@@ -17,11 +17,12 @@ package process_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/w6d-io/hook"
 	"github.com/w6d-io/process-rest/internal/config"
 
 	"github.com/w6d-io/process-rest/internal/process"
@@ -48,71 +49,181 @@ exit 1
 var _ = Describe("Process", func() {
 	Context("Execute", func() {
 		var (
-			dir       string
-			filename  string
-			filename2 string
-			err       error
+			dir     string
+			success string
+			failure string
 		)
 		BeforeEach(func() {
-			dir, err = ioutil.TempDir("", "test_dir")
-			Expect(err).To(Succeed())
-			_, err = ioutil.TempDir(dir, "directory")
-			Expect(err).To(Succeed())
-			filename = dir + string(os.PathSeparator) + "script1.sh"
-			err = ioutil.WriteFile(filename, []byte(successTest), 0755)
-			Expect(err).To(Succeed())
-			filename2 = dir + string(os.PathSeparator) + "script2.sh"
-			err = ioutil.WriteFile(filename2, []byte(failTest), 0755)
+			var err error
+			dir, err = os.MkdirTemp("", "test_dir")
 			Expect(err).To(Succeed())
+			success = filepath.Join(dir, "success")
+			failure = filepath.Join(dir, "failure")
+			writeScript(success, "script1.sh", successTest)
+			writeScript(failure, "script2.sh", failTest)
 		})
 		AfterEach(func() {
-			config.Reset()
-			err = os.RemoveAll(dir)
-			Expect(err).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
 		})
+		run := func(c config.Config) error {
+			e := newExecutor(c)
+			defer e.Close()
+			return execute(e, "")
+		}
 		It("Do nothing", func() {
-			config.AddPreScript("")
-			config.AddMainScript("")
-			config.AddPostScript("")
-			Expect(config.Validate()).To(Equal(false))
-			process.Execute("")
-
+			_, err := config.New(config.Config{})
+			Expect(err).To(HaveOccurred())
 		})
 		It("runs pre script with success", func() {
-			config.AddPreScript(filename)
-			process.Execute("")
-			//Expect(err).To(Succeed())
+			Expect(run(config.Config{PreScriptFolder: success, MainScriptFolder: success})).To(Succeed())
 		})
 		It("runs main script with success", func() {
-			err := hook.Subscribe(context.Background(), "http://localhost:8888", ".*")
-			Expect(err).To(Succeed())
-			config.AddMainScript(filename)
-			p := new(process.Process)
-			err = p.MainProcess()
+			e := newExecutor(config.Config{MainScriptFolder: success})
+			defer e.Close()
+			p := newProcess(e, "")
+			err := p.MainProcess(context.Background())
 			Expect(err).To(Succeed())
 		})
 		It("runs post script with success", func() {
-			config.AddPostScript(filename)
-			process.Execute("")
-			//Expect(err).To(Succeed())
+			Expect(run(config.Config{MainScriptFolder: success, PostScriptFolder: success})).To(Succeed())
 		})
 		It("runs pre script with failure", func() {
-			config.AddPreScript(filename2)
-			process.Execute("")
-			//Expect(err).ToNot(Succeed())
-			//Expect(err.Error()).To(ContainSubstring("pre process failed"))
+			err := run(config.Config{PreScriptFolder: failure, MainScriptFolder: success})
+			Expect(err).ToNot(Succeed())
+			Expect(err.(*process.Error).GetStage()).To(Equal("pre"))
 		})
 		It("runs main script with failure", func() {
-			config.AddMainScript(filename2)
-			process.Execute("")
-			//Expect(err).ToNot(Succeed())
-			//Expect(err.Error()).To(ContainSubstring("main process failed "))
+			err := run(config.Config{MainScriptFolder: failure})
+			Expect(err).ToNot(Succeed())
+			Expect(err.(*process.Error).GetStage()).To(Equal("main"))
 		})
 		It("runs post script with failure", func() {
-			config.AddPostScript(filename2)
-			process.Execute("")
-			//Expect(err).ToNot(Succeed())
-			//Expect(err.Error()).To(ContainSubstring("post process failed"))
+			err := run(config.Config{MainScriptFolder: success, PostScriptFolder: failure})
+			Expect(err).ToNot(Succeed())
+			Expect(err.(*process.Error).GetStage()).To(Equal("post"))
+		})
+		It("keeps the scripts it started with on reload", func() {
+			sleep := filepath.Join(dir, "sleep")
+			writeScript(sleep, "sleep.sh", "#!/bin/bash\nsleep 0.3\n")
+			source, reload := newSource(config.Config{MainScriptFolder: sleep})
+			e := process.NewExecutor(source, process.NewMemoryRegistry())
+			defer e.Close()
+			p := newProcess(e, "reload")
+			done := make(chan error)
+			go func() { done <- p.Execute(context.Background()) }()
+			time.Sleep(100 * time.Millisecond)
+			reload(config.Config{MainScriptFolder: sleep, PostScriptFolder: failure})
+			Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+			Expect(p.Outputs).To(HaveLen(1))
+		})
+		It("runs the scripts of the named pipeline", func() {
+			folder := filepath.Join(dir, "deploy")
+			writeScript(folder, "deploy.sh", successTest)
+			e := newExecutor(config.Config{
+				MainScriptFolder: failure,
+				Pipelines:        []config.Pipeline{{Name: "deploy", MainScriptFolder: folder}},
+			})
+			defer e.Close()
+			p, err := e.New("test", "deploy")
+			Expect(err).To(Succeed())
+			Expect(p.Execute(context.Background())).To(Succeed())
+			Expect(p.Outputs).To(HaveLen(1))
+			Expect(p.Outputs[0].Name).To(Equal("deploy.sh"))
+			Expect(p.GetStatus("test", nil).Pipeline).To(Equal("deploy"))
+			_, err = e.New("test", "unknown")
+			Expect(err).To(MatchError(process.ErrPipelineNotFound))
+		})
+		It("fails when its pipeline is removed before it starts", func() {
+			folder := filepath.Join(dir, "deploy")
+			writeScript(folder, "deploy.sh", successTest)
+			source, reload := newSource(config.Config{
+				MainScriptFolder: success,
+				Pipelines:        []config.Pipeline{{Name: "deploy", MainScriptFolder: folder}},
+			})
+			e := process.NewExecutor(source, process.NewMemoryRegistry())
+			defer e.Close()
+			p, err := e.New("removed", "deploy")
+			Expect(err).To(Succeed())
+			reload(config.Config{MainScriptFolder: success})
+			err = p.Execute(context.Background())
+			Expect(errors.Is(err, process.ErrPipelineNotFound)).To(BeTrue())
+			Expect(p.Outputs).To(BeEmpty())
+		})
+	})
+	Context("output", func() {
+		It("captures stdout, stderr and exit code", func() {
+			result, err := process.Run(context.Background(), nil, nil, "bash", "-c", "echo out; echo err >&2; exit 3")
+			Expect(err).To(HaveOccurred())
+			Expect(result.Stdout).To(Equal("out\n"))
+			Expect(result.Stderr).To(Equal("err\n"))
+			Expect(result.ExitCode).To(Equal(3))
+			Expect(result.StartedAt).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(result.Duration).To(BeNumerically(">", 0))
+		})
+		It("captures large outputs on both streams", func() {
+			result, err := process.Run(context.Background(), nil, nil, "bash", "-c",
+				"head -c 1048576 /dev/zero | tr '\\0' a >&2; head -c 1048576 /dev/zero | tr '\\0' b")
+			Expect(err).To(Succeed())
+			Expect(result.Stdout).To(HaveLen(1048576))
+			Expect(result.Stderr).To(HaveLen(1048576))
+			Expect(result.ExitCode).To(Equal(0))
+		})
+		It("records both streams in the script output", func() {
+			dir, err := os.MkdirTemp("", "output_dir")
+			Expect(err).To(Succeed())
+			defer func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			}()
+			writeScript(dir, "fail.sh", "#!/bin/bash\necho out\necho why >&2\nexit 2\n")
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			Expect(p.Execute(context.Background())).ToNot(Succeed())
+			Expect(p.Outputs).To(HaveLen(1))
+			Expect(p.Outputs[0].Stdout).To(Equal("out\n"))
+			Expect(p.Outputs[0].Stderr).To(Equal("why\n"))
+			Expect(p.Outputs[0].Log).To(Equal("why\n"))
+			Expect(p.Outputs[0].ExitCode).To(Equal(2))
+			Expect(p.Outputs[0].Duration).To(BeNumerically(">", 0))
+		})
+	})
+	Context("timeout", func() {
+		var (
+			dir string
+			err error
+		)
+		BeforeEach(func() {
+			dir, err = os.MkdirTemp("", "timeout_dir")
+			Expect(err).To(Succeed())
+			writeScript(dir, "sleep.sh", "#!/bin/bash\nsleep 10 &\nwait\n")
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("kills the script when the script timeout expires", func() {
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			p.ScriptTimeout = 200 * time.Millisecond
+			start := time.Now()
+			err := p.Execute(context.Background())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(err).To(HaveOccurred())
+			var timeoutErr *process.TimeoutError
+			Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+			Expect(p.Outputs).To(HaveLen(1))
+			Expect(p.Outputs[0].Status).To(Equal(process.StatusTimedOut))
+		})
+		It("kills the script when the job timeout expires", func() {
+			e := newExecutor(config.Config{PreScriptFolder: dir, MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			p.Timeout = 200 * time.Millisecond
+			err := p.Execute(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*process.Error).GetStage()).To(Equal("pre"))
+			Expect(p.Outputs).To(HaveLen(1))
+			Expect(p.Outputs[0].Status).To(Equal(process.StatusTimedOut))
 		})
 	})
 	Context("get message", func() {
@@ -131,4 +242,204 @@ var _ = Describe("Process", func() {
 			Expect(s).To(Equal(`{{"error": "test"},{"script":"test.sh", "error":"not found", "status":"failed", "log":"no such test"}}`))
 		})
 	})
+	Context("get status", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "status_dir")
+			Expect(err).To(Succeed())
+			writeScript(dir, "script1.sh", successTest)
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		newStatusProcess := func(legacy bool) *process.Process {
+			e := newExecutor(config.Config{MainScriptFolder: dir, HookLegacyLog: legacy})
+			defer e.Close()
+			return newProcess(e, "test")
+		}
+		It("marshals outputs with quotes and new lines", func() {
+			p := newStatusProcess(false)
+			p.Outputs = []process.Output{{
+				Name:   "test.sh",
+				Status: "failed",
+				Log:    "say \"hello\"\nworld",
+				Error:  "exit status 1",
+			}}
+			err := process.NewError(errors.New("exit status 1"), process.CodeMainProcess, "main process failed")
+			b, errM := json.Marshal(p.GetStatus("test", err))
+			Expect(errM).To(Succeed())
+			Expect(json.Valid(b)).To(BeTrue())
+			status := &process.Status{}
+			Expect(json.Unmarshal(b, status)).To(Succeed())
+			Expect(status.Version).To(Equal(process.StatusVersion))
+			Expect(status.Stage).To(Equal("main"))
+			Expect(status.Success).To(BeFalse())
+			Expect(status.Log).To(BeEmpty())
+			Expect(status.Outputs).To(HaveLen(1))
+			Expect(status.Outputs[0].Log).To(Equal("say \"hello\"\nworld"))
+		})
+		It("keeps the legacy log when enabled", func() {
+			p := newStatusProcess(true)
+			err := process.NewError(errors.New("test"), process.CodePreProcess, "pre process failed")
+			status := p.GetStatus("test", err)
+			Expect(status.Log).To(Equal(`{{"error": "test"}}`))
+			Expect(status.Stage).To(Equal("pre"))
+		})
+		It("succeeds without error", func() {
+			status := newStatusProcess(false).GetStatus("test", nil)
+			Expect(status.Success).To(BeTrue())
+			Expect(status.Error).To(BeEmpty())
+			Expect(status.Outputs).NotTo(BeNil())
+		})
+	})
+	Context("command", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "command dir")
+			Expect(err).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("returns the command of the interpreter", func() {
+			name, args, err := process.Command("", "/scripts/a.py", "values.yaml")
+			Expect(err).To(Succeed())
+			Expect(name).To(Equal("bash"))
+			Expect(args).To(Equal([]string{"/scripts/a.py", "values.yaml"}))
+			name, args, err = process.Command(config.InterpreterShebang, "/scripts/a.py", "values.yaml")
+			Expect(err).To(Succeed())
+			Expect(name).To(Equal("/scripts/a.py"))
+			Expect(args).To(Equal([]string{"values.yaml"}))
+			name, args, err = process.Command(config.InterpreterPython, "/scripts/a.py", "values.yaml")
+			Expect(err).To(Succeed())
+			Expect(name).To(Equal("python3"))
+			Expect(args).To(Equal([]string{"/scripts/a.py", "values.yaml"}))
+			_, _, err = process.Command("perl", "/scripts/a.py")
+			Expect(err).To(HaveOccurred())
+		})
+		It("passes the same arguments to each script", func() {
+			script := `#!/bin/bash
+printf '%s|' "$@"
+`
+			writeScript(dir, "01 first.sh", script)
+			writeScript(dir, "02 second.sh", script)
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			Expect(p.MainProcess(context.Background(), "a b", "$(id)")).To(Succeed())
+			Expect(p.Outputs).To(HaveLen(2))
+			Expect(p.Outputs[0].Stdout).To(Equal("a b|$(id)|"))
+			Expect(p.Outputs[1].Stdout).To(Equal("a b|$(id)|"))
+		})
+		It("runs a script without exec bit by default", func() {
+			script := dir + string(os.PathSeparator) + "script.sh"
+			Expect(os.WriteFile(script, []byte("echo \"$1\"\n"), 0644)).To(Succeed())
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			Expect(p.MainProcess(context.Background(), "value")).To(Succeed())
+			Expect(p.Outputs[0].Stdout).To(Equal("value\n"))
+		})
+		It("runs a script without exec bit with sh", func() {
+			script := dir + string(os.PathSeparator) + "script.sh"
+			Expect(os.WriteFile(script, []byte("echo \"$1\"\n"), 0644)).To(Succeed())
+			e := newExecutor(config.Config{MainScriptFolder: dir, Interpreter: config.InterpreterSh})
+			defer e.Close()
+			p := newProcess(e, "")
+			Expect(p.MainProcess(context.Background(), "value")).To(Succeed())
+			Expect(p.Outputs[0].Stdout).To(Equal("value\n"))
+		})
+	})
+	Context("script options", func() {
+		var dir string
+		script := func(name string, content string) string {
+			return writeScript(dir, name, "#!/bin/bash\n"+content)
+		}
+		manifest := func(content string) {
+			Expect(os.WriteFile(filepath.Join(dir, config.ManifestFile), []byte(content), 0644)).To(Succeed())
+		}
+		run := func(payload []byte) (*process.Process, error) {
+			e := newExecutor(config.Config{MainScriptFolder: dir})
+			defer e.Close()
+			p := newProcess(e, "")
+			p.Payload = payload
+			return p, p.MainProcess(context.Background())
+		}
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "options")
+			Expect(err).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("retries the script with backoff", func() {
+			counter := filepath.Join(dir, "counter")
+			script("retry.sh", "echo run >> "+counter+"\n[ $(wc -l < "+counter+") -ge 3 ]\n")
+			manifest("scripts:\n  - name: retry.sh\n    retries: 3\n    backoff: 10ms\n")
+			p, err := run(nil)
+			Expect(err).To(Succeed())
+			Expect(p.Outputs).To(HaveLen(1))
+			Expect(p.Outputs[0].Attempts).To(Equal(3))
+			Expect(p.Outputs[0].Status).To(Equal("succeeded"))
+		})
+		It("fails once the retries are exhausted", func() {
+			script("fail.sh", "exit 1\n")
+			manifest("scripts:\n  - name: fail.sh\n    retries: 1\n")
+			p, err := run(nil)
+			Expect(err).ToNot(Succeed())
+			Expect(p.Outputs[0].Attempts).To(Equal(2))
+		})
+		It("allows the failure", func() {
+			script("fail.sh", "exit 1\n")
+			script("next.sh", "exit 0\n")
+			manifest("scripts:\n  - name: fail.sh\n    allow_failure: true\n")
+			p, err := run(nil)
+			Expect(err).To(Succeed())
+			Expect(p.Outputs).To(HaveLen(2))
+			Expect(p.Outputs[0].Status).To(Equal("failed"))
+			Expect(p.Outputs[0].AllowedFailure).To(BeTrue())
+		})
+		It("skips the disabled script and the unmet condition", func() {
+			script("disabled.sh", "exit 1\n")
+			script("condition.sh", "exit 1\n")
+			script("matched.sh", "exit 0\n")
+			manifest(`scripts:
+  - name: disabled.sh
+    enabled: false
+  - name: condition.sh
+    condition: '$.env == "prod"'
+  - name: matched.sh
+    condition: $.redis.enabled
+`)
+			p, err := run([]byte(`{"env": "dev", "redis": {"enabled": true}}`))
+			Expect(err).To(Succeed())
+			Expect(p.Outputs).To(HaveLen(3))
+			Expect(p.Outputs[0].Status).To(Equal(process.StatusSkipped))
+			Expect(p.Outputs[1].Status).To(Equal(process.StatusSkipped))
+			Expect(p.Outputs[2].Status).To(Equal("succeeded"))
+		})
+		It("applies the script timeout", func() {
+			script("sleep.sh", "sleep 5\n")
+			manifest("scripts:\n  - name: sleep.sh\n    timeout: 100ms\n")
+			p, err := run(nil)
+			var timeoutErr *process.TimeoutError
+			Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+			Expect(p.Outputs[0].Status).To(Equal(process.StatusTimedOut))
+		})
+	})
+	Context("match", func() {
+		payload := map[string]interface{}{"env": "dev", "replicas": float64(0), "redis": map[string]interface{}{"enabled": true}}
+		It("tests the condition on the payload", func() {
+			Expect(process.Match(nil, payload)).To(BeTrue())
+			Expect(process.Match(&config.Condition{Path: "$.redis.enabled"}, payload)).To(BeTrue())
+			Expect(process.Match(&config.Condition{Path: "$.redis.enabled", Not: true}, payload)).To(BeFalse())
+			Expect(process.Match(&config.Condition{Path: "$.replicas"}, payload)).To(BeFalse())
+			Expect(process.Match(&config.Condition{Path: "$.missing"}, payload)).To(BeFalse())
+			Expect(process.Match(&config.Condition{Path: "$.env", Operator: "==", Value: "dev"}, payload)).To(BeTrue())
+			Expect(process.Match(&config.Condition{Path: "$.env", Operator: "!=", Value: "dev"}, payload)).To(BeFalse())
+		})
+	})
 })
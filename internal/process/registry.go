@@ -0,0 +1,227 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/w6d-io/x/logx"
+)
+
+// MemoryRegistry is the in-memory implementation of Registry
+type MemoryRegistry struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+// NewMemoryRegistry returns an empty in-memory registry
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{jobs: make(map[string]Job)}
+}
+
+// Save creates or replaces the job record
+func (r *MemoryRegistry) Save(job Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = job.copy()
+	return nil
+}
+
+// Get returns the job record matching the id
+func (r *MemoryRegistry) Get(id string) (Job, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return job.copy(), true
+}
+
+// List returns all the job records sorted by creation date
+func (r *MemoryRegistry) List() []Job {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	jobs := make([]Job, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		jobs = append(jobs, job.copy())
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Finished returns whether the state is a final one
+func (s State) Finished() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCancelled
+}
+
+// Delete removes the job record
+func (r *MemoryRegistry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, id)
+	return nil
+}
+
+// copy returns the job with its own outputs slice
+func (j Job) copy() Job {
+	j.Outputs = append([]Output(nil), j.Outputs...)
+	return j
+}
+
+// Register records a new job in queued state. It fails with ErrJobInProgress
+// when a job with the same id is not finished yet
+func (e *Executor) Register(id string) error {
+	e.registerMu.Lock()
+	defer e.registerMu.Unlock()
+	e.evict()
+	if job, ok := e.registry.Get(id); ok && !job.State.Finished() {
+		return ErrJobInProgress
+	}
+	e.resetLogs(id)
+	return e.registry.Save(Job{
+		ID:        id,
+		State:     StateQueued,
+		CreatedAt: time.Now(),
+		Outputs:   []Output{},
+	})
+}
+
+// Unregister removes the job record, its logs and its pending cancellation
+func (e *Executor) Unregister(id string) error {
+	e.dropLogs(id)
+	e.clearPending(id)
+	return e.registry.Delete(id)
+}
+
+// GetJob returns the job record matching the id
+func (e *Executor) GetJob(id string) (Job, bool) {
+	job, ok := e.registry.Get(id)
+	if ok {
+		e.setPosition(&job)
+	}
+	return job, ok
+}
+
+// ListJobs returns all the job records
+func (e *Executor) ListJobs() []Job {
+	jobs := e.registry.List()
+	for i := range jobs {
+		e.setPosition(&jobs[i])
+	}
+	return jobs
+}
+
+// expireJobs removes the finished jobs kept over the retention, then the
+// oldest ones over the maximum number of finished jobs
+func (e *Executor) expireJobs() {
+	e.registerMu.Lock()
+	defer e.registerMu.Unlock()
+	e.evict()
+}
+
+// evict removes the expired finished jobs, registerMu must be held
+func (e *Executor) evict() {
+	log := logx.WithName(nil, "Executor.evict")
+	s := e.source()
+	var finished []Job
+	for _, job := range e.registry.List() {
+		if job.State.Finished() && job.FinishedAt != nil {
+			finished = append(finished, job)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].FinishedAt.Before(*finished[j].FinishedAt)
+	})
+	expiry := time.Now().Add(-s.GetJobRetention())
+	for i, job := range finished {
+		if job.FinishedAt.After(expiry) && len(finished)-i <= s.GetMaxFinishedJobs() {
+			continue
+		}
+		log.V(1).Info("evict job", "id", job.ID)
+		e.dropLogs(job.ID)
+		e.clearPending(job.ID)
+		if err := e.registry.Delete(job.ID); err != nil {
+			log.Error(err, "delete job failed", "id", job.ID)
+		}
+	}
+}
+
+// setPosition sets the position of the queued job in the pool
+func (e *Executor) setPosition(job *Job) {
+	if job.State == StateQueued {
+		job.Position = e.pool.Position(job.ID)
+	}
+}
+
+// setState moves the process to the state and records it
+func (p *Process) setState(state State, err error) {
+	p.state = state
+	if state.Finished() {
+		p.collectArtifacts()
+	}
+	p.record(err)
+	if state.Finished() {
+		if p.executor != nil {
+			p.executor.clearPending(p.ID)
+			p.executor.closeLogs(p.ID)
+			p.executor.expireJobs()
+		}
+		p.releaseWorkspace()
+	}
+}
+
+// record saves the state reached by the process in the registry of its
+// executor
+func (p *Process) record(err error) {
+	log := logx.WithName(nil, "Process.record")
+	if p.executor == nil || p.ID == "" || p.state == "" {
+		return
+	}
+	state := p.state
+	registry := p.executor.registry
+	job, ok := registry.Get(p.ID)
+	if !ok {
+		log.V(1).Info("job not registered", "id", p.ID)
+		return
+	}
+	now := time.Now()
+	if job.StartedAt == nil && state != StateQueued {
+		job.StartedAt = &now
+	}
+	if state.Finished() {
+		job.FinishedAt = &now
+	}
+	job.State = state
+	job.Pipeline = p.Pipeline
+	job.LockKey = p.LockKey
+	job.Outputs = append([]Output{}, p.Outputs...)
+	job.Artifacts = p.Artifacts
+	job.Variables = p.copyVariables()
+	job.Steps = append([]StepStatus(nil), p.Steps...)
+	if err != nil {
+		job.Error = err.Error()
+	}
+	if err := registry.Save(job); err != nil {
+		log.Error(err, "save job failed", "id", p.ID)
+	}
+}
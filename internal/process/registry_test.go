@@ -0,0 +1,140 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Registry", func() {
+	Context("memory", func() {
+		It("saves and returns copies of the job", func() {
+			r := process.NewMemoryRegistry()
+			job := process.Job{ID: "1", State: process.StateQueued, Outputs: []process.Output{{Name: "a"}}}
+			Expect(r.Save(job)).To(Succeed())
+			got, ok := r.Get("1")
+			Expect(ok).To(BeTrue())
+			Expect(got.State).To(Equal(process.StateQueued))
+			got.Outputs[0].Name = "b"
+			again, _ := r.Get("1")
+			Expect(again.Outputs[0].Name).To(Equal("a"))
+		})
+		It("does not find unknown job", func() {
+			r := process.NewMemoryRegistry()
+			_, ok := r.Get("unknown")
+			Expect(ok).To(BeFalse())
+		})
+		It("lists jobs by creation date", func() {
+			r := process.NewMemoryRegistry()
+			now := time.Now()
+			Expect(r.Save(process.Job{ID: "2", CreatedAt: now.Add(time.Second)})).To(Succeed())
+			Expect(r.Save(process.Job{ID: "1", CreatedAt: now})).To(Succeed())
+			jobs := r.List()
+			Expect(jobs).To(HaveLen(2))
+			Expect(jobs[0].ID).To(Equal("1"))
+			Expect(jobs[1].ID).To(Equal("2"))
+		})
+	})
+	Context("execution", func() {
+		var (
+			dir      string
+			err      error
+			executor *process.Executor
+		)
+		load := func(c config.Config) {
+			executor.Close()
+			executor = newExecutor(c)
+		}
+		BeforeEach(func() {
+			dir, err = os.MkdirTemp("", "registry_dir")
+			Expect(err).To(Succeed())
+			writeScript(filepath.Join(dir, "success"), "script1.sh", successTest)
+			writeScript(filepath.Join(dir, "failure"), "script2.sh", failTest)
+			executor = newExecutor(config.Config{MainScriptFolder: filepath.Join(dir, "success")})
+		})
+		AfterEach(func() {
+			executor.Close()
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+		It("refuses a job in progress", func() {
+			Expect(executor.Register("1")).To(Succeed())
+			Expect(executor.Register("1")).To(MatchError(process.ErrJobInProgress))
+		})
+		It("accepts a finished job id again", func() {
+			Expect(executor.Register("1")).To(Succeed())
+			Expect(execute(executor, "1")).To(Succeed())
+			Expect(executor.Register("1")).To(Succeed())
+		})
+		It("evicts the finished jobs over the retention", func() {
+			load(config.Config{MainScriptFolder: filepath.Join(dir, "success"), JobRetention: 100 * time.Millisecond})
+			Expect(executor.Register("1")).To(Succeed())
+			Expect(execute(executor, "1")).To(Succeed())
+			_, ok := executor.GetJob("1")
+			Expect(ok).To(BeTrue())
+			time.Sleep(150 * time.Millisecond)
+			Expect(executor.Register("2")).To(Succeed())
+			_, ok = executor.GetJob("1")
+			Expect(ok).To(BeFalse())
+			_, ok = executor.GetJob("2")
+			Expect(ok).To(BeTrue())
+		})
+		It("evicts the oldest finished jobs over the maximum", func() {
+			load(config.Config{MainScriptFolder: filepath.Join(dir, "success"), MaxFinishedJobs: 1})
+			for _, id := range []string{"1", "2"} {
+				Expect(executor.Register(id)).To(Succeed())
+				Expect(execute(executor, id)).To(Succeed())
+			}
+			Expect(executor.Register("3")).To(Succeed())
+			jobs := executor.ListJobs()
+			Expect(jobs).To(HaveLen(2))
+			Expect(jobs[0].ID).To(Equal("2"))
+			Expect(jobs[1].ID).To(Equal("3"))
+		})
+		It("records a succeeded job", func() {
+			Expect(executor.Register("job-1")).To(Succeed())
+			job, ok := executor.GetJob("job-1")
+			Expect(ok).To(BeTrue())
+			Expect(job.State).To(Equal(process.StateQueued))
+			execute(executor, "job-1")
+			job, _ = executor.GetJob("job-1")
+			Expect(job.State).To(Equal(process.StateSucceeded))
+			Expect(job.StartedAt).ToNot(BeNil())
+			Expect(job.FinishedAt).ToNot(BeNil())
+			Expect(job.Outputs).To(HaveLen(1))
+			Expect(executor.ListJobs()).To(HaveLen(1))
+		})
+		It("records a failed job", func() {
+			load(config.Config{MainScriptFolder: filepath.Join(dir, "failure")})
+			Expect(executor.Register("job-2")).To(Succeed())
+			execute(executor, "job-2")
+			job, ok := executor.GetJob("job-2")
+			Expect(ok).To(BeTrue())
+			Expect(job.State).To(Equal(process.StateFailed))
+			Expect(job.Error).ToNot(BeEmpty())
+			Expect(job.Outputs[0].Status).To(Equal("failed"))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/x/logx"
+)
+
+// stepResult is the outcome of a step run
+type stepResult struct {
+	index  int
+	output Output
+	err    error
+}
+
+// RunSteps runs the steps as soon as the steps they need succeeded, the ready
+// steps at the same time. The steps needing a failed step are skipped while
+// the others go on. It returns the first failure once all the steps are over
+func (p *Process) RunSteps(ctx context.Context, steps []config.Step, arg ...string) error {
+	log := logx.WithName(ctx, "Process.RunSteps")
+	index := make(map[string]int, len(steps))
+	p.Steps = make([]StepStatus, len(steps))
+	for i, s := range steps {
+		index[s.Name] = i
+		p.Steps[i] = StepStatus{Name: s.Name, State: StepPending, Needs: s.Needs}
+	}
+	p.record(nil)
+	results := make(chan stepResult)
+	running := 0
+	var first error
+	for {
+		cancelled := errors.Is(context.Cause(ctx), ErrCancelled)
+		for i, s := range steps {
+			if cancelled || p.Steps[i].State != StepPending {
+				continue
+			}
+			ready := true
+			for _, need := range s.Needs {
+				switch p.Steps[index[need]].State {
+				case StepSucceeded:
+				case StepFailed, StepSkipped, StepCancelled:
+					p.Steps[i].State = StepSkipped
+					p.Steps[i].Error = fmt.Sprintf("needed step %s did not succeed", need)
+					ready = false
+				default:
+					ready = false
+				}
+				if !ready {
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			script, err := s.GetScript()
+			if err != nil {
+				p.Steps[i].State = StepFailed
+				p.Steps[i].Error = err.Error()
+				if first == nil {
+					first = err
+				}
+				continue
+			}
+			log.Info("start step", "step", s.Name)
+			p.Steps[i].State = StepRunning
+			running++
+			go func(i int, script config.Script, env []string) {
+				o, err := p.step(ctx, script, env, arg...)
+				results <- stepResult{index: i, output: o, err: err}
+			}(i, script, p.variablesEnv())
+		}
+		// the skipped steps may unlock others, look again before waiting
+		if p.pendingReady(steps, index) && !cancelled {
+			continue
+		}
+		p.record(nil)
+		if running == 0 {
+			break
+		}
+		r := <-results
+		running--
+		status := &p.Steps[r.index]
+		switch {
+		case r.err == nil && r.output.Status == StatusSkipped:
+			status.State = StepSkipped
+			status.Error = r.output.Log
+		case r.err == nil:
+			status.State = StepSucceeded
+		case stopped(r.err):
+			status.State = StepCancelled
+			status.Error = r.err.Error()
+		default:
+			log.Error(r.err, "step failed", "step", status.Name)
+			status.State = StepFailed
+			status.Error = r.err.Error()
+			if first == nil {
+				first = r.err
+			}
+		}
+		p.addOutput(r.output)
+	}
+	if errors.Is(context.Cause(ctx), ErrCancelled) {
+		for i := range p.Steps {
+			if p.Steps[i].State == StepPending {
+				p.Steps[i].State = StepCancelled
+			}
+		}
+		p.record(nil)
+		return ErrCancelled
+	}
+	return first
+}
+
+// pendingReady returns whether a pending step can be started or skipped
+func (p *Process) pendingReady(steps []config.Step, index map[string]int) bool {
+	for i, s := range steps {
+		if p.Steps[i].State != StepPending {
+			continue
+		}
+		ready := true
+		for _, need := range s.Needs {
+			if p.Steps[index[need]].State == StepPending || p.Steps[index[need]].State == StepRunning {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Steps", func() {
+	var (
+		dir      string
+		executor *process.Executor
+	)
+	script := func(name string, content string) string {
+		filename := filepath.Join(dir, name)
+		Expect(os.WriteFile(filename, []byte("#!/bin/bash\n"+content), 0755)).To(Succeed())
+		return filename
+	}
+	states := func(p *process.Process) map[string]process.StepState {
+		m := make(map[string]process.StepState)
+		for _, s := range p.Steps {
+			m[s.Name] = s.State
+		}
+		return m
+	}
+	load := func(steps ...config.Step) {
+		executor = newExecutor(config.Config{Steps: steps})
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "steps")
+		Expect(err).To(Succeed())
+	})
+	AfterEach(func() {
+		executor.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("runs the ready steps at the same time", func() {
+		load(
+			config.Step{Name: "build", Run: script("build.sh", "echo digest=sha >> $OUTPUTS\n")},
+			config.Step{Name: "test", Run: script("test.sh", "sleep 0.5\n"), Needs: []string{"build"}},
+			config.Step{Name: "lint", Run: script("lint.sh", "sleep 0.5\n"), Needs: []string{"build"}},
+			config.Step{Name: "deploy", Run: script("deploy.sh", "echo -n $OUTPUT_DIGEST\n"), Needs: []string{"test", "lint"}},
+		)
+		Expect(executor.Register("steps")).To(Succeed())
+		p := newProcess(executor, "steps")
+		start := time.Now()
+		Expect(p.Execute(context.Background())).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically("<", 900*time.Millisecond))
+		Expect(p.Outputs).To(HaveLen(4))
+		Expect(p.Outputs[0].Name).To(Equal("build.sh"))
+		Expect(p.Outputs[3].Name).To(Equal("deploy.sh"))
+		Expect(p.Outputs[3].Stdout).To(Equal("sha"))
+		Expect(states(p)).To(Equal(map[string]process.StepState{
+			"build": process.StepSucceeded, "test": process.StepSucceeded,
+			"lint": process.StepSucceeded, "deploy": process.StepSucceeded,
+		}))
+		job, ok := executor.GetJob("steps")
+		Expect(ok).To(BeTrue())
+		Expect(job.Steps).To(HaveLen(4))
+		Expect(job.Steps[3].Needs).To(Equal([]string{"test", "lint"}))
+	})
+	It("skips the steps needing a failed step", func() {
+		load(
+			config.Step{Name: "build", Run: script("build.sh", "exit 1\n")},
+			config.Step{Name: "deploy", Run: script("deploy.sh", "exit 0\n"), Needs: []string{"build"}},
+			config.Step{Name: "notify", Run: script("notify.sh", "exit 0\n"), Needs: []string{"deploy"}},
+			config.Step{Name: "docs", Run: script("docs.sh", "sleep 0.2\n")},
+		)
+		p := newProcess(executor, "")
+		err := p.MainProcess(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+		Expect(states(p)).To(Equal(map[string]process.StepState{
+			"build": process.StepFailed, "deploy": process.StepSkipped,
+			"notify": process.StepSkipped, "docs": process.StepSucceeded,
+		}))
+		Expect(p.Outputs).To(HaveLen(2))
+	})
+	It("skips the step whose condition is not met", func() {
+		load(
+			config.Step{Name: "deploy", Run: script("deploy.sh", "exit 1\n"), Condition: "$.deploy"},
+			config.Step{Name: "notify", Run: script("notify.sh", "exit 0\n"), Needs: []string{"deploy"}},
+		)
+		p := newProcess(executor, "")
+		Expect(p.MainProcess(context.Background())).To(Succeed())
+		Expect(states(p)).To(Equal(map[string]process.StepState{
+			"deploy": process.StepSkipped, "notify": process.StepSkipped,
+		}))
+	})
+	It("cancels the pending steps", func() {
+		load(
+			config.Step{Name: "long", Run: script("long.sh", "sleep 5\n")},
+			config.Step{Name: "next", Run: script("next.sh", "exit 0\n"), Needs: []string{"long"}},
+		)
+		Expect(executor.Register("steps-cancel")).To(Succeed())
+		p := newProcess(executor, "steps-cancel")
+		done := make(chan error)
+		go func() { done <- p.Execute(context.Background()) }()
+		Eventually(func() error { return executor.Cancel("steps-cancel") }).Should(Succeed())
+		Eventually(done, 15*time.Second).Should(Receive(HaveOccurred()))
+		Expect(states(p)).To(Equal(map[string]process.StepState{
+			"long": process.StepCancelled, "next": process.StepCancelled,
+		}))
+	})
+})
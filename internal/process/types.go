@@ -17,25 +17,253 @@ Created on 20/03/2021
 
 package process
 
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
 type Output struct {
 	Name   string `json:"name"   yaml:"name"`
 	Status string `json:"status" yaml:"status"`
-	Log    string `json:"log"    yaml:"log"`
-	Error  string `json:"error"  yaml:"error"`
+	// Log is the stdout of the script, or its stderr when it failed
+	Log       string        `json:"log"        yaml:"log"`
+	Error     string        `json:"error"      yaml:"error"`
+	Stdout    string        `json:"stdout"     yaml:"stdout"`
+	Stderr    string        `json:"stderr"     yaml:"stderr"`
+	ExitCode  int           `json:"exit_code"  yaml:"exit_code"`
+	StartedAt time.Time     `json:"started_at" yaml:"started_at"`
+	Duration  time.Duration `json:"duration"   yaml:"duration"`
+	// Attempts is the number of runs of the script
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	// AllowedFailure is set when the script failed without failing the job
+	AllowedFailure bool `json:"allowed_failure,omitempty" yaml:"allowed_failure,omitempty"`
+	// Variables are written by the script in its $OUTPUTS file
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+// Options are the inputs and outputs of a command run
+type Options struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	Stdin  io.Reader
+	// Dir is the working directory, the one of the service when empty
+	Dir string
+	// Env is added to the environment of the service
+	Env []string
 }
 
+// Result is the outcome of a command run
+type Result struct {
+	Stdout    string
+	Stderr    string
+	ExitCode  int
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+const (
+	// CodePreProcess is the error code of a pre script failure
+	CodePreProcess = 550
+	// CodeMainProcess is the error code of a main script failure
+	CodeMainProcess = 551
+	// CodePostProcess is the error code of a post script failure
+	CodePostProcess = 552
+	// CodeCancelled is the error code of a cancelled process
+	CodeCancelled = 553
+	// CodeFinallyProcess is the error code of a finally script failure
+	CodeFinallyProcess = 554
+)
+
 type Error struct {
 	Cause   error
 	Code    int
 	Message string
 }
 
+// Line is a line written by a script
+type Line struct {
+	Seq    int       `json:"seq"`
+	Time   time.Time `json:"time"`
+	Script string    `json:"script"`
+	Stream string    `json:"stream"`
+	Text   string    `json:"text"`
+}
+
+const (
+	// StatusTimedOut is the output status of a script killed on timeout
+	StatusTimedOut = "timed-out"
+	// StatusCancelled is the output status of a script killed on cancellation
+	StatusCancelled = "cancelled"
+	// StatusSkipped is the status of a script disabled or whose condition is not met
+	StatusSkipped = "skipped"
+)
+
+// TimeoutError is returned when a script is killed because the script or
+// the job timeout expired
+type TimeoutError struct {
+	Script string
+	Cause  error
+}
+
+// StatusVersion is the version of the Status schema
+const StatusVersion = "2"
+
+// Status is the payload sent to the hooks
 type Status struct {
-	ID      string `json:"id"`
-	Success bool   `json:"success"`
-	Log     string `json:"log"`
+	Version   string   `json:"version"`
+	ID        string   `json:"id"`
+	Pipeline  string   `json:"pipeline,omitempty"`
+	Success   bool     `json:"success"`
+	TimedOut  bool     `json:"timed_out,omitempty"`
+	Cancelled bool     `json:"cancelled,omitempty"`
+	Stage     string   `json:"stage,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Outputs   []Output `json:"outputs"`
+	// Variables are written by the scripts in their $OUTPUTS file
+	Variables map[string]string `json:"variables,omitempty"`
+	// Log is the legacy message, only set with hook_legacy_log
+	Log string `json:"log,omitempty"`
 }
 
 type Process struct {
-	Outputs []Output `json:"outputs"`
+	ID string `json:"id"`
+	// Pipeline is the name of the pipeline run, empty for the default one
+	Pipeline      string        `json:"pipeline,omitempty"`
+	Timeout       time.Duration `json:"timeout,omitempty"`
+	ScriptTimeout time.Duration `json:"script_timeout,omitempty"`
+	// LockKey serializes the processes sharing the same key
+	LockKey string `json:"lock_key,omitempty"`
+	// Supersede cancels the queued processes with the same lock key
+	Supersede bool     `json:"supersede,omitempty"`
+	Outputs   []Output `json:"outputs"`
+	// Env is added to the environment of the scripts
+	Env []string `json:"-"`
+	// Stdin is written on the standard input of each script
+	Stdin []byte `json:"-"`
+	// Payload is the JSON payload the script conditions are tested on
+	Payload []byte `json:"-"`
+	// Workspace is the working directory of the scripts
+	Workspace string `json:"workspace,omitempty"`
+	// Artifacts are the files collected from the workspace
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// Variables are written by the scripts in their $OUTPUTS file
+	Variables map[string]string `json:"variables,omitempty"`
+	// Steps are the states of the pipeline steps
+	Steps []StepStatus `json:"steps,omitempty"`
+	// Concurrency bounds the running jobs of the pipeline
+	Concurrency int `json:"-"`
+
+	mu     sync.Mutex
+	source config.Source
+	// executor tracks the job, none for a process run on its own
+	executor *Executor
+	config   *config.Pipeline
+	state    State
+	ran      map[string]bool
+	values   map[string]interface{}
+}
+
+// State is the step reached by a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StatePre       State = "pre"
+	StateMain      State = "main"
+	StatePost      State = "post"
+	StateFinally   State = "finally"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Job is the record of an execution kept by the registry
+type Job struct {
+	ID         string     `json:"id"`
+	Pipeline   string     `json:"pipeline,omitempty"`
+	State      State      `json:"state"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Position   int        `json:"position,omitempty"`
+	LockKey    string     `json:"lock_key,omitempty"`
+	Outputs    []Output   `json:"outputs"`
+	Artifacts  []Artifact `json:"artifacts,omitempty"`
+	// Variables are written by the scripts in their $OUTPUTS file
+	Variables map[string]string `json:"variables,omitempty"`
+	// Steps are the states of the pipeline steps
+	Steps []StepStatus `json:"steps,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+// StepState is the step reached by a pipeline step
+type StepState string
+
+const (
+	StepPending   StepState = "pending"
+	StepRunning   StepState = "running"
+	StepSucceeded StepState = "succeeded"
+	StepFailed    StepState = "failed"
+	StepSkipped   StepState = "skipped"
+	StepCancelled StepState = "cancelled"
+)
+
+// StepStatus is the state of a pipeline step
+type StepStatus struct {
+	Name  string    `json:"name"`
+	State StepState `json:"state"`
+	Needs []string  `json:"needs,omitempty"`
+	Error string    `json:"error,omitempty"`
 }
+
+// Artifact is a file left by the scripts in the artifacts folder
+type Artifact struct {
+	// Path is relative to the artifacts folder
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Registry keeps track of the jobs
+type Registry interface {
+	// Save creates or replaces the job record
+	Save(job Job) error
+	// Get returns the job record matching the id
+	Get(id string) (Job, bool)
+	// List returns all the job records sorted by creation date
+	List() []Job
+	// Delete removes the job record
+	Delete(id string) error
+}
+
+var (
+	// GracePeriod is the delay between SIGTERM and SIGKILL sent to a script
+	GracePeriod = 10 * time.Second
+	// WaitDelay bounds the wait for the script output once it is killed
+	WaitDelay = 10 * time.Second
+
+	// ErrCancelled is the cause of a process cancelled on request
+	ErrCancelled = errors.New("cancelled")
+	// ErrJobNotFound is returned when the job id is unknown
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling a finished job
+	ErrJobFinished = errors.New("job already finished")
+	// ErrFailFast stops the scripts of a parallel group on the first failure
+	ErrFailFast = errors.New("stopped by a failure of the group")
+	// ErrArtifactNotFound is returned when the artifact is unknown or expired
+	ErrArtifactNotFound = errors.New("artifact not found")
+
+	// ErrQueueFull is returned when the pool cannot queue more jobs
+	ErrQueueFull = errors.New("queue is full")
+	// ErrPoolClosed is returned when submitting to a closed pool
+	ErrPoolClosed = errors.New("pool is closed")
+	// ErrPipelineNotFound is returned for an unknown pipeline name
+	ErrPipelineNotFound = errors.New("pipeline not found")
+
+	// ErrJobInProgress is returned when registering an id already in flight
+	ErrJobInProgress = errors.New("job already in progress")
+)
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/w6d-io/x/logx"
+)
+
+const (
+	// WorkspaceEnv holds the path of the job workspace
+	WorkspaceEnv = "WORKSPACE"
+	// workspacePrefix is the name prefix of the job workspaces
+	workspacePrefix = "process-rest-"
+)
+
+// CreateWorkspace creates the working directory of the scripts of the process
+func (p *Process) CreateWorkspace() error {
+	dir, err := os.MkdirTemp(p.snapshot().GetWorkspaceDir(), workspacePrefix+"*")
+	if err != nil {
+		return err
+	}
+	p.Workspace = dir
+	p.Env = append(p.Env, WorkspaceEnv+"="+dir)
+	return nil
+}
+
+// RemoveWorkspace removes the working directory of the process
+func (p *Process) RemoveWorkspace() {
+	log := logx.WithName(nil, "Process.RemoveWorkspace")
+	if p.Workspace == "" {
+		return
+	}
+	if err := os.RemoveAll(p.Workspace); err != nil {
+		log.Error(err, "remove workspace failed", "id", p.ID, "workspace", p.Workspace)
+	}
+}
+
+// releaseWorkspace removes the working directory once the process is over.
+// The workspace of a failed process is kept during the retention
+func (p *Process) releaseWorkspace() {
+	retention := p.snapshot().GetWorkspaceRetention()
+	if p.state != StateFailed || retention <= 0 {
+		p.RemoveWorkspace()
+		return
+	}
+	ws := &Process{ID: p.ID, Workspace: p.Workspace}
+	time.AfterFunc(retention, ws.RemoveWorkspace)
+}
+
+// PruneWorkspaces removes the job workspaces left over the retention, e.g.
+// by a previous run of the service
+func (e *Executor) PruneWorkspaces() error {
+	log := logx.WithName(nil, "Process.PruneWorkspaces")
+	s := e.source()
+	entries, err := os.ReadDir(s.GetWorkspaceDir())
+	if err != nil {
+		return err
+	}
+	retention := s.GetWorkspaceRetention()
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), workspacePrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < retention {
+			continue
+		}
+		dir := filepath.Join(s.GetWorkspaceDir(), entry.Name())
+		log.V(1).Info("remove workspace", "workspace", dir)
+		if err := os.RemoveAll(dir); err != nil {
+			log.Error(err, "remove workspace failed", "workspace", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+var _ = Describe("Workspace", func() {
+	var (
+		dir      string
+		executor *process.Executor
+		reload   func(config.Config)
+	)
+	// load returns the configuration of the script run in the workspaces
+	load := func(content string, retention time.Duration) config.Config {
+		scripts := filepath.Join(dir, "scripts")
+		writeScript(scripts, "script.sh", content)
+		return config.Config{MainScriptFolder: scripts, WorkspaceDir: dir, WorkspaceRetention: retention}
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "workspaces")
+		Expect(err).To(Succeed())
+		var source config.Source
+		source, reload = newSource(load("#!/bin/bash\n", 0))
+		executor = process.NewExecutor(source, process.NewMemoryRegistry())
+	})
+	AfterEach(func() {
+		executor.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("runs the scripts in the workspace and removes it on success", func() {
+		reload(load("#!/bin/bash\npwd\necho $WORKSPACE\n", 0))
+		p := newProcess(executor, "workspace-success")
+		Expect(executor.Register(p.ID)).To(Succeed())
+		Expect(p.CreateWorkspace()).To(Succeed())
+		Expect(p.Workspace).To(BeADirectory())
+		Expect(p.Execute(context.Background())).To(Succeed())
+		Expect(p.Outputs[0].Stdout).To(Equal(p.Workspace + "\n" + p.Workspace + "\n"))
+		Expect(p.Workspace).ToNot(BeADirectory())
+	})
+	It("keeps the workspace of a failed job during the retention", func() {
+		reload(load("#!/bin/bash\nexit 1\n", 200*time.Millisecond))
+		p := newProcess(executor, "workspace-failure")
+		Expect(executor.Register(p.ID)).To(Succeed())
+		Expect(p.CreateWorkspace()).To(Succeed())
+		Expect(p.Execute(context.Background())).ToNot(Succeed())
+		Expect(p.Workspace).To(BeADirectory())
+		Eventually(func() bool {
+			_, err := os.Stat(p.Workspace)
+			return os.IsNotExist(err)
+		}, time.Second*2).Should(BeTrue())
+	})
+	It("prunes the workspaces over the retention", func() {
+		p := newProcess(executor, "prune")
+		Expect(p.CreateWorkspace()).To(Succeed())
+		other := filepath.Join(dir, "other")
+		Expect(os.Mkdir(other, 0755)).To(Succeed())
+		reload(load("#!/bin/bash\n", time.Hour))
+		Expect(executor.PruneWorkspaces()).To(Succeed())
+		Expect(p.Workspace).To(BeADirectory())
+		reload(load("#!/bin/bash\n", 0))
+		Expect(executor.PruneWorkspaces()).To(Succeed())
+		Expect(p.Workspace).ToNot(BeADirectory())
+		Expect(other).To(BeADirectory())
+	})
+})
@@ -17,6 +17,8 @@ package health
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/w6d-io/process-rest/internal/config"
 	"github.com/w6d-io/process-rest/pkg/router"
 )
 
@@ -26,7 +28,8 @@ func init() {
 	router.AddGet("/health", Health)
 }
 
-// Health call for liveliness and readiness
+// Health call for liveliness and readiness. It holds the revision of the
+// active configuration
 func Health(c *gin.Context) {
-	c.JSON(200, gin.H{"status": "ok"})
+	c.JSON(200, gin.H{"status": "ok", "revision": config.GetSnapshot().Revision})
 }
@@ -0,0 +1,54 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+// ListArtifacts handle GET on /process/:id/artifacts
+func (h *Handler) ListArtifacts(c *gin.Context) {
+	ID := c.Param("id")
+	artifacts, err := h.executor.ListArtifacts(ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{Status: "error", Message: err.Error(), ID: ID})
+		return
+	}
+	c.JSON(http.StatusOK, artifacts)
+}
+
+// GetArtifact handle GET on /process/:id/artifacts/*path
+func (h *Handler) GetArtifact(c *gin.Context) {
+	ID := c.Param("id")
+	file, artifact, err := h.executor.GetArtifact(ID, c.Param("path"))
+	if err != nil {
+		if errors.Is(err, process.ErrJobNotFound) || errors.Is(err, process.ErrArtifactNotFound) {
+			c.JSON(http.StatusNotFound, Response{Status: "error", Message: err.Error(), ID: ID})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{Status: "error", Message: "get artifact failed", Error: err, ID: ID})
+		return
+	}
+	c.FileAttachment(file, path.Base(artifact.Path))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	internal "github.com/w6d-io/process-rest/internal/process"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Artifacts", func() {
+	var (
+		dir      string
+		executor *internal.Executor
+		h        *process.Handler
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "artifacts")
+		Expect(err).To(Succeed())
+		folder := filepath.Join(dir, "scripts")
+		Expect(os.Mkdir(folder, 0755)).To(Succeed())
+		script := filepath.Join(folder, "artifacts.sh")
+		Expect(os.WriteFile(script, []byte("#!/bin/bash\nmkdir artifacts\necho diff > artifacts/helm.diff\n"), 0755)).To(Succeed())
+		executor = newExecutor(config.Config{
+			MainScriptFolder: folder,
+			WorkspaceDir:     dir,
+			ArtifactDir:      filepath.Join(dir, "store"),
+		})
+		h = process.NewHandler(executor)
+		p, err := executor.New("job-1", "")
+		Expect(err).To(Succeed())
+		Expect(executor.Register("job-1")).To(Succeed())
+		Expect(p.CreateWorkspace()).To(Succeed())
+		Expect(p.Execute(context.Background())).To(Succeed())
+	})
+	AfterEach(func() {
+		executor.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("lists the artifacts", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "job-1"}}
+		h.ListArtifacts(c)
+		Expect(w.Code).To(Equal(200))
+		Expect(w.Body.String()).To(ContainSubstring(`"path":"helm.diff"`))
+	})
+	It("returns 404 for unknown job", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "unknown"}}
+		h.ListArtifacts(c)
+		Expect(w.Code).To(Equal(404))
+	})
+	It("downloads the artifact", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/process/job-1/artifacts/helm.diff", nil)
+		c.Params = gin.Params{{Key: "id", Value: "job-1"}, {Key: "path", Value: "/helm.diff"}}
+		h.GetArtifact(c)
+		Expect(w.Code).To(Equal(200))
+		Expect(w.Body.String()).To(Equal("diff\n"))
+		Expect(w.Header().Get("Content-Disposition")).To(ContainSubstring("helm.diff"))
+	})
+	It("returns 404 for unknown artifact", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/process/job-1/artifacts/../config.yaml", nil)
+		c.Params = gin.Params{{Key: "id", Value: "job-1"}, {Key: "path", Value: "/../config.yaml"}}
+		h.GetArtifact(c)
+		Expect(w.Code).To(Equal(404))
+	})
+})
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+// Cancel handle DELETE on /process/:id
+func (h *Handler) Cancel(c *gin.Context) {
+	ID := c.Param("id")
+	if err := h.executor.Cancel(ID); err != nil {
+		switch {
+		case errors.Is(err, process.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, Response{Status: "error", Message: err.Error(), ID: ID})
+		case errors.Is(err, process.ErrJobFinished):
+			c.JSON(http.StatusConflict, Response{Status: "error", Message: err.Error(), ID: ID})
+		default:
+			c.JSON(http.StatusInternalServerError, Response{Status: "error", Message: "cancel failed", Error: err, ID: ID})
+		}
+		return
+	}
+	c.Header("Location", GetStatusURL(ID))
+	c.JSON(http.StatusAccepted, Response{Status: "succeed", Message: "cancelling...", ID: ID})
+}
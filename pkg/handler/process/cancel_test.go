@@ -0,0 +1,65 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	internal "github.com/w6d-io/process-rest/internal/process"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Cancel", func() {
+	var (
+		executor *internal.Executor
+		h        *process.Handler
+	)
+	cancel := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		h.Cancel(c)
+		return w
+	}
+	BeforeEach(func() {
+		executor = newExecutor(config.Config{MainScriptFolder: scripts})
+		h = process.NewHandler(executor)
+	})
+	AfterEach(func() {
+		executor.Close()
+	})
+	It("returns 404 for unknown job", func() {
+		Expect(cancel("unknown").Code).To(Equal(404))
+	})
+	It("returns 409 for finished job", func() {
+		Expect(executor.Register("done")).To(Succeed())
+		Expect(execute(executor, "done")).To(Succeed())
+		Expect(cancel("done").Code).To(Equal(409))
+	})
+	It("returns 202 for job in progress", func() {
+		Expect(executor.Register("queued")).To(Succeed())
+		w := cancel("queued")
+		Expect(w.Code).To(Equal(202))
+		Expect(w.Header().Get("Location")).To(Equal("/process/queued"))
+	})
+})
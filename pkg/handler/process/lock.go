@@ -0,0 +1,57 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+// LockKeyHeader is the http header holding the lock key
+const LockKeyHeader = "X-Lock-Key"
+
+// GetLockKey returns the key serializing the job. It is read from the
+// X-Lock-Key header, then the lock query parameter, then the path into the
+// payload configured for the pipeline
+func GetLockKey(c *gin.Context, pl *config.Pipeline, payload Payload) string {
+	if c.Request != nil {
+		if key := c.Request.Header.Get(LockKeyHeader); key != "" {
+			return key
+		}
+	}
+	if key := c.Query("lock"); key != "" {
+		return key
+	}
+	if pl == nil {
+		return ""
+	}
+	if path := pl.GetLockKeyPath(); path != "" {
+		if value, ok := Lookup(payload, path); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// Lookup returns the scalar value at the JSONPath like path ($.a.b[0].c) of
+// the payload
+func Lookup(payload Payload, path string) (string, bool) {
+	return process.Lookup(payload, path)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Lock", func() {
+	payload := process.Payload{
+		"release": map[string]interface{}{
+			"name":     "redis",
+			"replicas": float64(3),
+			"charts":   []interface{}{map[string]interface{}{"name": "first"}},
+		},
+	}
+	newContext := func(query string, header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		URL, err := url.Parse("http://localhost:8888/process?" + query)
+		Expect(err).To(Succeed())
+		c.Request = &http.Request{URL: URL, Header: http.Header{}}
+		if header != "" {
+			c.Request.Header.Set(process.LockKeyHeader, header)
+		}
+		return c
+	}
+	pipeline := func(path string) *config.Pipeline {
+		pl, _ := newSnapshot(config.Config{MainScriptFolder: scripts, LockKeyPath: path}).GetPipeline("")
+		return pl
+	}
+	It("reads the key from the header first", func() {
+		Expect(process.GetLockKey(newContext("lock=query", "header"), pipeline(""), payload)).To(Equal("header"))
+	})
+	It("reads the key from the query", func() {
+		Expect(process.GetLockKey(newContext("lock=query", ""), pipeline(""), payload)).To(Equal("query"))
+	})
+	It("reads the key from the payload", func() {
+		Expect(process.GetLockKey(newContext("", ""), pipeline("$.release.name"), payload)).To(Equal("redis"))
+	})
+	It("returns no key", func() {
+		Expect(process.GetLockKey(newContext("", ""), pipeline(""), payload)).To(BeEmpty())
+	})
+	It("returns no key without pipeline", func() {
+		Expect(process.GetLockKey(newContext("", ""), nil, payload)).To(BeEmpty())
+	})
+	It("looks up the payload", func() {
+		value, ok := process.Lookup(payload, "$.release.charts[0].name")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("first"))
+		value, ok = process.Lookup(payload, "release.replicas")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("3"))
+		_, ok = process.Lookup(payload, "$.release")
+		Expect(ok).To(BeFalse())
+		_, ok = process.Lookup(payload, "$.release.charts[1].name")
+		Expect(ok).To(BeFalse())
+		_, ok = process.Lookup(payload, "$.release.charts[x]")
+		Expect(ok).To(BeFalse())
+		_, ok = process.Lookup(payload, "$.missing")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Logs handle GET on /process/:id/logs. The script output lines are sent as
+// server-sent events, until the end of the job when follow is set
+func (h *Handler) Logs(c *gin.Context) {
+	ID := c.Param("id")
+	follow, err := strconv.ParseBool(c.DefaultQuery("follow", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{Status: "error", Message: "invalid follow parameter", ID: ID})
+		return
+	}
+	buffer, ok := h.executor.GetLogs(ID)
+	if !ok {
+		c.JSON(http.StatusNotFound, Response{Status: "error", Message: "job not found", ID: ID})
+		return
+	}
+	since := 0
+	if last, err := strconv.Atoi(c.GetHeader("Last-Event-ID")); err == nil {
+		since = last + 1
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Type", sse.ContentType)
+	c.Status(http.StatusOK)
+	for {
+		lines, next, changed, closed := buffer.Since(since)
+		for _, line := range lines {
+			c.Render(-1, sse.Event{Id: strconv.Itoa(line.Seq), Event: "log", Data: line})
+		}
+		since = next
+		if follow && closed {
+			job, _ := h.executor.GetJob(ID)
+			c.Render(-1, sse.Event{Event: "end", Data: job})
+		}
+		c.Writer.Flush()
+		if !follow || closed {
+			return
+		}
+		select {
+		case <-changed:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	internal "github.com/w6d-io/process-rest/internal/process"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Logs", func() {
+	var (
+		executor *internal.Executor
+		h        *process.Handler
+	)
+	logs := func(id, query string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		URL, err := url.Parse("http://localhost:8888/process/" + id + "/logs?" + query)
+		Expect(err).To(Succeed())
+		c.Request = (&http.Request{URL: URL, Header: header})
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		h.Logs(c)
+		return w
+	}
+	BeforeEach(func() {
+		executor = newExecutor(config.Config{MainScriptFolder: scripts})
+		h = process.NewHandler(executor)
+		Expect(executor.Register("job")).To(Succeed())
+		buffer, _ := executor.GetLogs("job")
+		buffer.Append(internal.Line{Script: "a.sh", Stream: "stdout", Text: "first"})
+		buffer.Append(internal.Line{Script: "a.sh", Stream: "stderr", Text: "second"})
+	})
+	AfterEach(func() {
+		executor.Close()
+	})
+	It("returns 404 for unknown job", func() {
+		Expect(logs("unknown", "", http.Header{}).Code).To(Equal(404))
+	})
+	It("returns 400 on invalid follow", func() {
+		Expect(logs("job", "follow=maybe", http.Header{}).Code).To(Equal(400))
+	})
+	It("sends the kept lines", func() {
+		w := logs("job", "", http.Header{})
+		Expect(w.Code).To(Equal(200))
+		Expect(w.Header().Get("Content-Type")).To(Equal("text/event-stream"))
+		Expect(w.Body.String()).To(ContainSubstring("id:0\nevent:log\n"))
+		Expect(w.Body.String()).To(ContainSubstring(`"text":"second"`))
+		Expect(w.Body.String()).ToNot(ContainSubstring("event:end"))
+	})
+	It("resumes after the last event id", func() {
+		w := logs("job", "", http.Header{"Last-Event-Id": []string{"0"}})
+		Expect(w.Body.String()).ToNot(ContainSubstring(`"text":"first"`))
+		Expect(w.Body.String()).To(ContainSubstring(`"text":"second"`))
+	})
+	It("follows the logs until the end of the job", func() {
+		Expect(execute(executor, "job")).To(Succeed())
+		w := logs("job", "follow=true", http.Header{})
+		Expect(w.Body.String()).To(ContainSubstring(`"text":"first"`))
+		Expect(w.Body.String()).To(ContainSubstring("event:end\n"))
+	})
+})
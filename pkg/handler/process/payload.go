@@ -0,0 +1,209 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gopkg.in/yaml.v3"
+)
+
+// UploadsFolder is the folder of the workspace where the uploaded files land
+const UploadsFolder = "uploads"
+
+// ContentTypes are the payload content types accepted on POST /process
+var ContentTypes = []string{
+	binding.MIMEJSON,
+	"application/yaml",
+	binding.MIMEYAML,
+	binding.MIMEPOSTForm,
+	binding.MIMEMultipartPOSTForm,
+}
+
+// BindPayload decodes the payload according to the content type of the
+// request, JSON when not set. The uploaded files are written into the dir and
+// their path set in the payload
+func BindPayload(c *gin.Context, dir string) (Payload, error) {
+	payload := make(Payload)
+	switch contentType(c) {
+	case "", binding.MIMEJSON:
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "unmarshal failed"}
+		}
+	case "application/yaml", binding.MIMEYAML:
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "read payload failed"}
+		}
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(body, &values); err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "unmarshal failed"}
+		}
+		if values != nil {
+			payload = values
+		}
+	case binding.MIMEPOSTForm:
+		if err := c.Request.ParseForm(); err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "parse form failed"}
+		}
+		if err := payload.setValues(c.Request.PostForm); err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "parse form failed"}
+		}
+	case binding.MIMEMultipartPOSTForm:
+		form, err := c.MultipartForm()
+		if err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "parse form failed"}
+		}
+		if err := payload.setValues(form.Value); err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "parse form failed"}
+		}
+		if err := payload.setFiles(form.File, filepath.Join(dir, UploadsFolder)); err != nil {
+			return nil, &ErrorProcess{Code: http.StatusBadRequest, Cause: err, Message: "upload failed"}
+		}
+	default:
+		return nil, &ErrorProcess{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf("unsupported content type %q, expected one of %s", contentType(c), strings.Join(ContentTypes, ", ")),
+		}
+	}
+	return payload, nil
+}
+
+// contentType returns the media type of the request
+func contentType(c *gin.Context) string {
+	if c.Request == nil || c.Request.Header == nil {
+		return ""
+	}
+	return c.ContentType()
+}
+
+// setValues sets the form values into the payload. The dotted keys are nested
+// and the repeated keys give a list
+func (payload Payload) setValues(values map[string][]string) error {
+	for key, value := range values {
+		var v interface{} = value[0]
+		if len(value) > 1 {
+			list := make([]interface{}, len(value))
+			for i := range value {
+				list[i] = value[i]
+			}
+			v = list
+		}
+		if err := payload.set(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setFiles writes the uploaded files into the dir and sets their path into the
+// payload
+func (payload Payload) setFiles(files map[string][]*multipart.FileHeader, dir string) error {
+	for key, headers := range files {
+		folder, err := uploadFolder(dir, key)
+		if err != nil {
+			return err
+		}
+		var paths []interface{}
+		for _, header := range headers {
+			name := filepath.Base(header.Filename)
+			if name == "." || name == ".." || name == string(filepath.Separator) {
+				return fmt.Errorf("invalid file name %q", header.Filename)
+			}
+			if err := os.MkdirAll(folder, 0755); err != nil {
+				return err
+			}
+			path := filepath.Join(folder, name)
+			if err := saveFile(header, path); err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+		var v interface{} = paths
+		if len(paths) == 1 {
+			v = paths[0]
+		}
+		if err := payload.set(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadFolder returns the folder of the dir holding the files of the field.
+// It fails when the field does not resolve into a folder of its own in the dir
+func uploadFolder(dir string, key string) (string, error) {
+	escaped := url.PathEscape(key)
+	folder := filepath.Join(dir, escaped)
+	rel, err := filepath.Rel(dir, folder)
+	if escaped == "." || escaped == ".." || err != nil || rel != escaped {
+		return "", fmt.Errorf("invalid field name %q", key)
+	}
+	return folder, nil
+}
+
+// set sets the value at the dotted key, creating the intermediate objects
+func (payload Payload) set(key string, value interface{}) error {
+	fields := strings.Split(key, ".")
+	current := map[string]interface{}(payload)
+	for _, field := range fields[:len(fields)-1] {
+		next, ok := current[field]
+		if !ok {
+			next = make(map[string]interface{})
+			current[field] = next
+		}
+		m, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field %s of %s is not an object", field, key)
+		}
+		current = m
+	}
+	last := fields[len(fields)-1]
+	if _, ok := current[last]; ok {
+		return fmt.Errorf("field %s set twice", key)
+	}
+	current[last] = value
+	return nil
+}
+
+// saveFile writes the uploaded file at the path
+func saveFile(header *multipart.FileHeader, path string) error {
+	src, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Payload", func() {
+	var dir string
+	request := func(contentType string, body *bytes.Buffer) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/process", body)
+		c.Request.Header.Set("Content-Type", contentType)
+		return c
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "payload")
+		Expect(err).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("binds a JSON payload", func() {
+		c := request("application/json; charset=utf-8", bytes.NewBufferString(`{"global": {"label": "test"}}`))
+		payload, err := process.BindPayload(c, dir)
+		Expect(err).To(Succeed())
+		Expect(payload).To(Equal(process.Payload{"global": map[string]interface{}{"label": "test"}}))
+		c = request("application/json", bytes.NewBufferString(`{"global": {`))
+		_, err = process.BindPayload(c, dir)
+		Expect(err.(process.Error).GetStatusCode()).To(Equal(http.StatusBadRequest))
+	})
+	It("binds a YAML payload", func() {
+		for _, contentType := range []string{"application/yaml", "application/x-yaml"} {
+			c := request(contentType, bytes.NewBufferString("global:\n  label: test\n  replicas: 2\n"))
+			payload, err := process.BindPayload(c, dir)
+			Expect(err).To(Succeed())
+			Expect(payload).To(Equal(process.Payload{"global": map[string]interface{}{"label": "test", "replicas": 2}}))
+		}
+		c := request("application/yaml", bytes.NewBufferString("global: ["))
+		_, err := process.BindPayload(c, dir)
+		Expect(err.(process.Error).GetStatusCode()).To(Equal(http.StatusBadRequest))
+	})
+	It("binds a form payload with nested and repeated keys", func() {
+		c := request("application/x-www-form-urlencoded", bytes.NewBufferString("global.label=test&tags=a&tags=b"))
+		payload, err := process.BindPayload(c, dir)
+		Expect(err).To(Succeed())
+		Expect(payload).To(Equal(process.Payload{
+			"global": map[string]interface{}{"label": "test"},
+			"tags":   []interface{}{"a", "b"},
+		}))
+		c = request("application/x-www-form-urlencoded", bytes.NewBufferString("global=test&global.label=test"))
+		_, err = process.BindPayload(c, dir)
+		Expect(err.(process.Error).GetStatusCode()).To(Equal(http.StatusBadRequest))
+	})
+	It("writes the uploaded files into the workspace", func() {
+		body := new(bytes.Buffer)
+		w := multipart.NewWriter(body)
+		Expect(w.WriteField("global.label", "test")).To(Succeed())
+		part, err := w.CreateFormFile("chart.values", "../values.yaml")
+		Expect(err).To(Succeed())
+		_, err = part.Write([]byte("replicas: 2\n"))
+		Expect(err).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		c := request(w.FormDataContentType(), body)
+		payload, err := process.BindPayload(c, dir)
+		Expect(err).To(Succeed())
+		path := filepath.Join(dir, process.UploadsFolder, "chart.values", "values.yaml")
+		Expect(payload).To(Equal(process.Payload{
+			"global": map[string]interface{}{"label": "test"},
+			"chart":  map[string]interface{}{"values": path},
+		}))
+		content, err := os.ReadFile(path)
+		Expect(err).To(Succeed())
+		Expect(string(content)).To(Equal("replicas: 2\n"))
+	})
+	It("rejects the fields resolving outside the uploads folder", func() {
+		for _, key := range []string{"..", "."} {
+			body := new(bytes.Buffer)
+			w := multipart.NewWriter(body)
+			part, err := w.CreateFormFile(key, "values.yaml")
+			Expect(err).To(Succeed())
+			_, err = part.Write([]byte("replicas: 2\n"))
+			Expect(err).To(Succeed())
+			Expect(w.Close()).To(Succeed())
+			c := request(w.FormDataContentType(), body)
+			_, err = process.BindPayload(c, dir)
+			Expect(err).To(HaveOccurred(), key)
+			Expect(err.(process.Error).GetStatusCode()).To(Equal(http.StatusBadRequest))
+			Expect(filepath.Join(dir, "values.yaml")).NotTo(BeAnExistingFile())
+		}
+	})
+	It("returns 415 on unsupported content type", func() {
+		c := request("text/plain", bytes.NewBufferString("label=test"))
+		_, err := process.BindPayload(c, dir)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(process.Error).GetStatusCode()).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(err.Error()).To(ContainSubstring(`unsupported content type "text/plain"`))
+		Expect(strings.Contains(err.Error(), "multipart/form-data")).To(BeTrue())
+	})
+})
@@ -19,12 +19,20 @@ package process
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
 
+	"github.com/w6d-io/process-rest/internal/config"
 	"github.com/w6d-io/process-rest/internal/process"
 	"github.com/w6d-io/process-rest/pkg/router"
 	"github.com/w6d-io/x/logx"
@@ -35,55 +43,153 @@ var (
 	YamlMarshal = yaml.Marshal
 	// IoTempFile is hack for unit-test
 	IoTempFile = os.CreateTemp
+	// WaitTimeout is the default duration a synchronous request waits for the job
+	WaitTimeout = 5 * time.Minute
 )
 
-func init() {
-	router.AddPost("/process", Process)
+// NewHandler returns a handler creating, running and tracking the processes
+// with the executor
+func NewHandler(executor *process.Executor) *Handler {
+	return &Handler{executor: executor}
 }
 
-// Process handle POST on /process
-func Process(c *gin.Context) {
-	filename, err := InitProcess(c)
+// AddRoutes binds the process routes of the router to the handler
+func (h *Handler) AddRoutes() {
+	router.AddPost("/process", h.Process)
+	router.AddPost("/process/:pipeline", h.Process)
+	router.AddGet("/process", h.List)
+	router.AddGet("/process/:id", h.Get)
+	router.AddDelete("/process/:id", h.Cancel)
+	router.AddGet("/process/:id/logs", h.Logs)
+	router.AddGet("/process/:id/artifacts", h.ListArtifacts)
+	router.AddGet("/process/:id/artifacts/*path", h.GetArtifact)
+}
+
+// Process handle POST on /process and /process/:pipeline
+func (h *Handler) Process(c *gin.Context) {
+	wait, timeout, err := GetWaitOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{Status: "error", Message: err.Error()})
+		return
+	}
+	jobTimeout, scriptTimeout, err := GetTimeouts(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{Status: "error", Message: err.Error()})
+		return
+	}
+	supersede, err := strconv.ParseBool(c.DefaultQuery("supersede", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{Status: "error", Message: "invalid supersede parameter"})
+		return
+	}
+	ID := c.Query("id")
+	if ID == "" {
+		ID = uuid.NewString()
+	}
+	p, err := h.executor.New(ID, c.Param("pipeline"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{Status: "error", Message: err.Error(), ID: ID})
+		return
+	}
+	if err := p.CreateWorkspace(); err != nil {
+		c.JSON(500, Response{Status: "error", Message: "create workspace failed", Error: err, ID: ID})
+		return
+	}
+	submitted := false
+	defer func() {
+		if !submitted {
+			p.RemoveWorkspace()
+		}
+	}()
+	payload, filename, err := InitProcess(c, p.Workspace)
 	if err != nil {
 		processError := err.(Error)
 		c.JSON(processError.GetStatusCode(), processError.GetResponse())
 		return
 	}
-	ID, _ := c.GetQuery("id")
-	go process.Execute(ID, filename)
-	c.JSON(200, Response{Message: "processing...", Status: "succeed"})
+	violations, err := ValidatePayload(p.GetPipeline(), payload)
+	if err != nil {
+		c.JSON(500, Response{Status: "error", Message: "validate payload failed", Error: err, ID: ID})
+		return
+	}
+	if len(violations) != 0 {
+		c.JSON(http.StatusUnprocessableEntity, Response{
+			Status:     "error",
+			Message:    "invalid payload",
+			ID:         ID,
+			Violations: violations,
+		})
+		return
+	}
+	args, err := SetPayload(p, payload, filename)
+	if err != nil {
+		c.JSON(500, Response{Status: "error", Message: "deliver payload failed", Error: err, ID: ID})
+		return
+	}
+	if err := h.executor.Register(ID); err != nil {
+		if errors.Is(err, process.ErrJobInProgress) {
+			c.JSON(http.StatusConflict, Response{Status: "error", Message: "job already in progress", ID: ID})
+			return
+		}
+		c.JSON(500, Response{Status: "error", Message: "register job failed", Error: err, ID: ID})
+		return
+	}
+	if jobTimeout != nil {
+		p.Timeout = *jobTimeout
+	}
+	if scriptTimeout != nil {
+		p.ScriptTimeout = *scriptTimeout
+	}
+	p.LockKey = GetLockKey(c, p.GetPipeline(), payload)
+	p.Supersede = supersede
+	done, err := h.executor.Submit(p, args...)
+	if err != nil {
+		if uerr := h.executor.Unregister(ID); uerr != nil {
+			log := logx.WithName(nil, "Process.Process")
+			log.Error(uerr, "unregister job failed", "id", ID)
+		}
+		if errors.Is(err, process.ErrQueueFull) {
+			c.JSON(http.StatusServiceUnavailable, Response{Status: "error", Message: err.Error(), ID: ID})
+			return
+		}
+		c.JSON(500, Response{Status: "error", Message: "submit job failed", Error: err, ID: ID})
+		return
+	}
+	submitted = true
+	c.Header("Location", GetStatusURL(ID))
+	if wait {
+		h.Wait(c, ID, done, timeout)
+		return
+	}
+	c.JSON(200, Response{Message: "processing...", Status: "succeed", ID: ID})
 }
 
-func InitProcess(c *gin.Context) (string, error) {
+// InitProcess binds the payload and writes it as YAML into the dir
+func InitProcess(c *gin.Context, dir string) (Payload, string, error) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, logx.CorrelationID, GetCorrelationID(c))
 	log := logx.WithName(ctx, "Process.InitProcess")
-	payload := new(Payload)
-	if err := c.BindJSON(payload); err != nil {
-		log.Error(err, "unmarshal failed")
-		return "", &ErrorProcess{Code: 500, Cause: err, Message: "unmarshal failed"}
+	payload, err := BindPayload(c, dir)
+	if err != nil {
+		log.Error(err, "bind payload failed")
+		return nil, "", err
 	}
 	values, err := YamlMarshal(payload)
 	if err != nil {
 		log.Error(err, "marshal payload failed")
-		return "", &ErrorProcess{Code: 500, Cause: err, Message: "marshal payload failed"}
+		return nil, "", &ErrorProcess{Code: 500, Cause: err, Message: "marshal payload failed"}
 	}
-	file, err := IoTempFile("", "values-*.yaml")
+	file, err := IoTempFile(dir, "values-*.yaml")
 	if err != nil {
 		log.Error(err, "create payload failed")
-		return "", &ErrorProcess{Code: 500, Cause: err, Message: "create payload failed"}
+		return nil, "", &ErrorProcess{Code: 500, Cause: err, Message: "create payload failed"}
 	}
+	defer func() { _ = file.Close() }()
 	if _, err := file.Write(values); err != nil {
 		log.Error(err, "write payload failed")
-		return "", &ErrorProcess{Code: 500, Cause: err, Message: "write payload failed"}
+		return nil, "", &ErrorProcess{Code: 500, Cause: err, Message: "write payload failed"}
 	}
-	filename := file.Name()
-	//err = file.Close()
-	if err != nil {
-		log.Error(err, "create payload failed")
-		return "", &ErrorProcess{Code: 500, Cause: err, Message: "create payload failed"}
-	}
-	return filename, nil
+	return payload, file.Name(), nil
 }
 
 func (e *ErrorProcess) Error() string {
@@ -105,6 +211,50 @@ func (e *ErrorProcess) GetResponse() Response {
 	}
 }
 
+// SetPayload delivers the payload to the scripts of the process and returns
+// the arguments of the scripts
+func SetPayload(p *process.Process, payload Payload, filename string) ([]string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.SetPayload(raw, filename); err != nil {
+		return nil, err
+	}
+	if pl := p.GetPipeline(); pl == nil || !pl.HasPayloadMode(config.PayloadModeFile) {
+		return nil, nil
+	}
+	return []string{filename}, nil
+}
+
+// GetTimeouts returns the job and script timeouts overridden by the request
+func GetTimeouts(c *gin.Context) (job *time.Duration, script *time.Duration, err error) {
+	if job, err = getDuration(c, "job_timeout"); err != nil {
+		return nil, nil, err
+	}
+	if script, err = getDuration(c, "script_timeout"); err != nil {
+		return nil, nil, err
+	}
+	return job, script, nil
+}
+
+func getDuration(c *gin.Context, key string) (*time.Duration, error) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return nil, fmt.Errorf("invalid %s parameter %q", key, value)
+	}
+	return &d, nil
+}
+
+// GetStatusURL returns the path where the job status can be fetched
+func GetStatusURL(id string) string {
+	return "/process/" + url.PathEscape(id)
+}
+
 func GetCorrelationID(ctx *gin.Context) string {
 	if ctx != nil && ctx.Writer != nil {
 		h := ctx.Writer.Header()
@@ -18,6 +18,8 @@ Created on 20/03/2021
 package process_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"go.uber.org/zap/zapcore"
@@ -30,6 +32,9 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// scripts is the folder of the main script of the default configuration
+var scripts string
+
 func Test(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, " Suite")
@@ -56,8 +61,14 @@ var _ = BeforeSuite(func(done Done) {
 		StacktraceLevel: zapcore.PanicLevel,
 	}
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts), zap.RawZapOpts(zapraw.AddCaller(), zapraw.AddCallerSkip(-1))))
+
+	var err error
+	scripts, err = os.MkdirTemp("", "scripts")
+	Expect(err).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(scripts, "main.sh"), []byte("#!/bin/bash\n"), 0755)).To(Succeed())
 	close(done)
 }, 60)
 
 var _ = AfterSuite(func() {
+	Expect(os.RemoveAll(scripts)).To(Succeed())
 })
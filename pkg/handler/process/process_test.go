@@ -18,6 +18,8 @@ Created on 20/03/2021
 package process_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -32,16 +34,25 @@ import (
 	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/util/framer"
 
+	"github.com/w6d-io/process-rest/internal/config"
+	internal "github.com/w6d-io/process-rest/internal/process"
 	"github.com/w6d-io/process-rest/pkg/handler/process"
 )
 
 var _ = Describe("Process", func() {
 	Context("", func() {
+		var (
+			executor *internal.Executor
+			h        *process.Handler
+		)
 		BeforeEach(func() {
 			process.YamlMarshal = yaml.Marshal
 			process.IoTempFile = os.CreateTemp
+			executor = newExecutor(config.Config{MainScriptFolder: scripts})
+			h = process.NewHandler(executor)
 		})
 		AfterEach(func() {
+			executor.Close()
 		})
 		It("payload well consisted", func() {
 			payload := `
@@ -59,7 +70,7 @@ var _ = Describe("Process", func() {
 				Body: framer.NewJSONFramedReader(r),
 				URL:  URL,
 			}
-			process.Process(c)
+			h.Process(c)
 			Expect(c.Writer.Status()).To(Equal(200))
 		})
 		It("payload well consisted, force yaml error", func() {
@@ -81,7 +92,7 @@ var _ = Describe("Process", func() {
 			process.YamlMarshal = func(in interface{}) (out []byte, err error) {
 				return nil, errors.New("yaml marshal error")
 			}
-			process.Process(c)
+			h.Process(c)
 			Expect(c.Writer.Status()).To(Equal(500))
 		})
 		It("payload well consisted, force iotemp creation error", func() {
@@ -103,10 +114,10 @@ var _ = Describe("Process", func() {
 			process.IoTempFile = func(dir, pattern string) (f *os.File, err error) {
 				return nil, errors.New("io temp error")
 			}
-			process.Process(c)
+			h.Process(c)
 			Expect(c.Writer.Status()).To(Equal(500))
 		})
-		It("return 500 due to malformed payload", func() {
+		It("return 400 due to malformed payload", func() {
 			payload := `
 {
   "global": { "label": "test-integration",
@@ -121,7 +132,7 @@ var _ = Describe("Process", func() {
 				Body: framer.NewJSONFramedReader(r),
 				URL:  URL,
 			}
-			process.Process(c)
+			h.Process(c)
 			Expect(c.Writer.Status()).To(Equal(400))
 		})
 		It("", func() {
@@ -140,9 +151,159 @@ var _ = Describe("Process", func() {
 				Body: framer.NewJSONFramedReader(r),
 				URL:  URL,
 			}
-			process.Process(c)
+			h.Process(c)
 			Expect(c.Writer.Status()).To(Equal(200))
 		})
+		It("generates the job id when not set", func() {
+			payload := `{"global": { "label": "test-integration" }}`
+			r := io.NopCloser(strings.NewReader(payload))
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: framer.NewJSONFramedReader(r),
+				URL:  URL,
+			}
+			h.Process(c)
+			Expect(c.Writer.Status()).To(Equal(200))
+			response := new(struct {
+				ID string `json:"id"`
+			})
+			Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+			Expect(response.ID).ToNot(BeEmpty())
+			Expect(w.Header().Get("Location")).To(Equal("/process/" + response.ID))
+		})
+		It("returns 409 when the job id is in progress", func() {
+			Expect(executor.Register("in-progress")).To(Succeed())
+			payload := `{"global": { "label": "test-integration" }}`
+			r := io.NopCloser(strings.NewReader(payload))
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process?id=in-progress")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: framer.NewJSONFramedReader(r),
+				URL:  URL,
+			}
+			h.Process(c)
+			Expect(c.Writer.Status()).To(Equal(409))
+		})
+		It("returns 503 when the queue is full", func() {
+			dir, err := os.MkdirTemp("", "full_dir")
+			Expect(err).To(Succeed())
+			defer func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			}()
+			filename := dir + string(os.PathSeparator) + "sleep.sh"
+			Expect(os.WriteFile(filename, []byte("#!/bin/bash\nsleep 0.5\n"), 0755)).To(Succeed())
+			executor.Close()
+			executor = newExecutor(config.Config{MainScriptFolder: dir, Concurrency: 1, QueueSize: 1})
+			h = process.NewHandler(executor)
+			submit := func(id string) {
+				Expect(executor.Register(id)).To(Succeed())
+				p, err := executor.New(id, "")
+				Expect(err).To(Succeed())
+				_, err = executor.Submit(p)
+				Expect(err).To(Succeed())
+			}
+			submit("busy")
+			Eventually(func() internal.State {
+				job, _ := executor.GetJob("busy")
+				return job.State
+			}).Should(Equal(internal.StateMain))
+			submit("waiting")
+			payload := `{"global": { "label": "test-integration" }}`
+			r := io.NopCloser(strings.NewReader(payload))
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process?id=full")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: framer.NewJSONFramedReader(r),
+				URL:  URL,
+			}
+			h.Process(c)
+			Expect(c.Writer.Status()).To(Equal(503))
+			_, ok := executor.GetJob("full")
+			Expect(ok).To(BeFalse())
+		})
+		It("returns 404 on unknown pipeline", func() {
+			payload := `{"global": { "label": "test-integration" }}`
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process/unknown")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: framer.NewJSONFramedReader(io.NopCloser(strings.NewReader(payload))),
+				URL:  URL,
+			}
+			c.Params = gin.Params{{Key: "pipeline", Value: "unknown"}}
+			h.Process(c)
+			Expect(c.Writer.Status()).To(Equal(404))
+		})
+		It("runs the named pipeline", func() {
+			dir, err := os.MkdirTemp("", "pipeline")
+			Expect(err).To(Succeed())
+			defer func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			}()
+			Expect(os.WriteFile(dir+string(os.PathSeparator)+"deploy.sh", []byte("#!/bin/bash\necho deploy\n"), 0755)).To(Succeed())
+			executor.Close()
+			executor = newExecutor(config.Config{
+				MainScriptFolder: scripts,
+				Pipelines:        []config.Pipeline{{Name: "deploy", MainScriptFolder: dir}},
+			})
+			h = process.NewHandler(executor)
+			payload := `{"global": { "label": "test-integration" }}`
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process/deploy?id=deploy&wait=true")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: framer.NewJSONFramedReader(io.NopCloser(strings.NewReader(payload))),
+				URL:  URL,
+			}
+			c.Params = gin.Params{{Key: "pipeline", Value: "deploy"}}
+			h.Process(c)
+			Expect(c.Writer.Status()).To(Equal(200))
+			job, ok := executor.GetJob("deploy")
+			Expect(ok).To(BeTrue())
+			Expect(job.Pipeline).To(Equal("deploy"))
+			Expect(job.Outputs).To(HaveLen(1))
+			Expect(job.Outputs[0].Stdout).To(Equal("deploy\n"))
+		})
+		It("runs the pipeline of the injected executor", func() {
+			dir, err := os.MkdirTemp("", "executor")
+			Expect(err).To(Succeed())
+			defer func() {
+				Expect(os.RemoveAll(dir)).To(Succeed())
+			}()
+			Expect(os.WriteFile(dir+string(os.PathSeparator)+"build.sh", []byte("#!/bin/bash\necho build\n"), 0755)).To(Succeed())
+			injected := newExecutor(config.Config{
+				MainScriptFolder: dir,
+				Pipelines:        []config.Pipeline{{Name: "build", MainScriptFolder: dir}},
+			})
+			defer injected.Close()
+			payload := `{"global": { "label": "test-integration" }}`
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process/build?id=injected&wait=true")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: framer.NewJSONFramedReader(io.NopCloser(strings.NewReader(payload))),
+				URL:  URL,
+			}
+			c.Params = gin.Params{{Key: "pipeline", Value: "build"}}
+			process.NewHandler(injected).Process(c)
+			Expect(c.Writer.Status()).To(Equal(200))
+			job, ok := injected.GetJob("injected")
+			Expect(ok).To(BeTrue())
+			Expect(job.Outputs).To(HaveLen(1))
+			Expect(job.Outputs[0].Stdout).To(Equal("build\n"))
+			_, ok = executor.GetSnapshot().GetPipeline("build")
+			Expect(ok).To(BeFalse())
+		})
 		It("get error Message", func() {
 			e := process.ErrorProcess{
 				Cause:   errors.New("test"),
@@ -157,5 +318,73 @@ var _ = Describe("Process", func() {
 			cid := process.GetCorrelationID(nil)
 			Expect(cid).ToNot(BeEmpty())
 		})
+		It("removes the workspace of a rejected payload", func() {
+			dir, err := os.MkdirTemp("", "workspaces")
+			Expect(err).To(Succeed())
+			defer func() { Expect(os.RemoveAll(dir)).To(Succeed()) }()
+			executor.Close()
+			executor = newExecutor(config.Config{MainScriptFolder: scripts, WorkspaceDir: dir})
+			h = process.NewHandler(executor)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			URL, err := url.Parse("http://localhost:8888/process")
+			Expect(err).To(Succeed())
+			c.Request = &http.Request{
+				Body: io.NopCloser(strings.NewReader(`{`)),
+				URL:  URL,
+			}
+			h.Process(c)
+			Expect(c.Writer.Status()).To(Equal(400))
+			entries, err := os.ReadDir(dir)
+			Expect(err).To(Succeed())
+			Expect(entries).To(BeEmpty())
+		})
+		It("delivers the payload according to the modes", func() {
+			dir, err := os.MkdirTemp("", "payload")
+			Expect(err).To(Succeed())
+			defer func() { Expect(os.RemoveAll(dir)).To(Succeed()) }()
+			filename := dir + string(os.PathSeparator) + "values.yaml"
+
+			p, err := executor.New("payload", "")
+			Expect(err).To(Succeed())
+			args, err := process.SetPayload(p, process.Payload{"name": "test"}, filename)
+			Expect(err).To(Succeed())
+			Expect(args).To(Equal([]string{filename}))
+			Expect(p.Env).To(BeEmpty())
+
+			modes := newExecutor(config.Config{
+				MainScriptFolder: scripts,
+				PayloadModes:     []string{config.PayloadModeEnv, config.PayloadModeStdin},
+			})
+			defer modes.Close()
+			p, err = modes.New("payload", "")
+			Expect(err).To(Succeed())
+			args, err = process.SetPayload(p, process.Payload{"name": "test"}, filename)
+			Expect(err).To(Succeed())
+			Expect(args).To(BeEmpty())
+			Expect(p.Env).To(Equal([]string{"PAYLOAD_NAME=test"}))
+			Expect(string(p.Stdin)).To(Equal(`{"name":"test"}`))
+		})
 	})
 })
+
+// execute runs a new process of the default pipeline tracked by e
+func execute(e *internal.Executor, id string) error {
+	p, err := e.New(id, "")
+	if err != nil {
+		return err
+	}
+	return p.Execute(context.Background())
+}
+
+// newSnapshot returns the snapshot of the configuration
+func newSnapshot(c config.Config) *config.Snapshot {
+	s, err := config.New(c)
+	Expect(err).To(Succeed())
+	return s
+}
+
+// newExecutor returns an executor of the snapshot of the configuration
+func newExecutor(c config.Config) *internal.Executor {
+	return internal.NewExecutor(config.Static(newSnapshot(c)), internal.NewMemoryRegistry())
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/w6d-io/process-rest/internal/config"
+)
+
+// Violation is a payload field not matching the JSON Schema
+type Violation struct {
+	// Field is the JSON pointer of the field, empty for the whole payload
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidatePayload returns the violations of the JSON Schema of the pipeline by
+// the payload, none when the pipeline has no schema
+func ValidatePayload(pl *config.Pipeline, payload Payload) ([]Violation, error) {
+	if pl == nil || pl.GetSchema() == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	err = pl.GetSchema().Validate(value)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return nil, err
+	}
+	violations := violations(ve)
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Field < violations[j].Field
+	})
+	return violations, nil
+}
+
+// violations returns the leaves of the validation error
+func violations(ve *jsonschema.ValidationError) []Violation {
+	if len(ve.Causes) == 0 {
+		return []Violation{{Field: ve.InstanceLocation, Message: ve.Message}}
+	}
+	var list []Violation
+	for _, cause := range ve.Causes {
+		list = append(list, violations(cause)...)
+	}
+	return list
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Schema", func() {
+	var (
+		dir      string
+		filename string
+	)
+	pipeline := func(schema string) *config.Pipeline {
+		pl, _ := newSnapshot(config.Config{MainScriptFolder: scripts, Schema: schema}).GetPipeline("")
+		return pl
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "schema")
+		Expect(err).To(Succeed())
+		filename = filepath.Join(dir, "schema.json")
+		Expect(os.WriteFile(filename, []byte(`{
+  "type": "object",
+  "required": ["global"],
+  "properties": {
+    "global": {
+      "type": "object",
+      "required": ["label"],
+      "properties": {
+        "label": {"type": "string"},
+        "replicas": {"type": "integer", "minimum": 1}
+      }
+    }
+  }
+}`), 0644)).To(Succeed())
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("accepts a valid payload", func() {
+		violations, err := process.ValidatePayload(pipeline(filename), process.Payload{
+			"global": map[string]interface{}{"label": "test", "replicas": 2},
+		})
+		Expect(err).To(Succeed())
+		Expect(violations).To(BeEmpty())
+	})
+	It("lists the field violations", func() {
+		violations, err := process.ValidatePayload(pipeline(filename), process.Payload{
+			"global": map[string]interface{}{"replicas": 0},
+		})
+		Expect(err).To(Succeed())
+		Expect(violations).To(HaveLen(2))
+		Expect(violations[0].Field).To(Equal("/global"))
+		Expect(violations[0].Message).To(ContainSubstring("label"))
+		Expect(violations[1].Field).To(Equal("/global/replicas"))
+	})
+	It("does not validate without pipeline", func() {
+		violations, err := process.ValidatePayload(nil, process.Payload{})
+		Expect(err).To(Succeed())
+		Expect(violations).To(BeEmpty())
+	})
+	It("does not validate without schema", func() {
+		violations, err := process.ValidatePayload(pipeline(""), process.Payload{})
+		Expect(err).To(Succeed())
+		Expect(violations).To(BeEmpty())
+	})
+	It("returns 422 before creating the job", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		URL, err := url.Parse("http://localhost:8888/process?id=invalid")
+		Expect(err).To(Succeed())
+		c.Request = &http.Request{
+			Body: io.NopCloser(strings.NewReader(`{"global": {"label": 1}}`)),
+			URL:  URL,
+		}
+		executor := newExecutor(config.Config{MainScriptFolder: scripts, Schema: filename})
+		defer executor.Close()
+		process.NewHandler(executor).Process(c)
+		Expect(c.Writer.Status()).To(Equal(http.StatusUnprocessableEntity))
+		response := new(struct {
+			Violations []process.Violation `json:"violations"`
+		})
+		Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		Expect(response.Violations).To(Equal([]process.Violation{
+			{Field: "/global/label", Message: "expected string, but got number"},
+		}))
+		_, ok := executor.GetJob("invalid")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,39 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// List handle GET on /process
+func (h *Handler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, h.executor.ListJobs())
+}
+
+// Get handle GET on /process/:id
+func (h *Handler) Get(c *gin.Context) {
+	job, ok := h.executor.GetJob(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, Response{Status: "error", Message: "job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
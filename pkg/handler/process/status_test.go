@@ -0,0 +1,69 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	internal "github.com/w6d-io/process-rest/internal/process"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Status", func() {
+	var (
+		executor *internal.Executor
+		h        *process.Handler
+	)
+	BeforeEach(func() {
+		executor = newExecutor(config.Config{MainScriptFolder: scripts})
+		h = process.NewHandler(executor)
+	})
+	AfterEach(func() {
+		executor.Close()
+	})
+	It("returns the job", func() {
+		Expect(executor.Register("job-1")).To(Succeed())
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "job-1"}}
+		h.Get(c)
+		Expect(w.Code).To(Equal(200))
+		Expect(w.Body.String()).To(ContainSubstring(`"state":"queued"`))
+	})
+	It("returns 404 for unknown job", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "unknown"}}
+		h.Get(c)
+		Expect(w.Code).To(Equal(404))
+	})
+	It("lists the jobs", func() {
+		Expect(executor.Register("job-1")).To(Succeed())
+		Expect(executor.Register("job-2")).To(Succeed())
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		h.List(c)
+		Expect(w.Code).To(Equal(200))
+		Expect(w.Body.String()).To(ContainSubstring(`"id":"job-2"`))
+	})
+})
@@ -17,6 +17,13 @@ Created on 20/03/2021
 
 package process
 
+import "github.com/w6d-io/process-rest/internal/process"
+
+// Handler serves the process requests with its executor
+type Handler struct {
+	executor *process.Executor
+}
+
 // payload is the values from request
 type Payload map[string]interface{}
 
@@ -35,7 +42,15 @@ type ErrorProcess struct {
 }
 
 type Response struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Error   error  `json:"error,omitempty"`
+	Status  string           `json:"status"`
+	Message string           `json:"message"`
+	ID      string           `json:"id,omitempty"`
+	Stage   string           `json:"stage,omitempty"`
+	Code    int              `json:"code,omitempty"`
+	Outputs []process.Output `json:"outputs,omitempty"`
+	// Variables are written by the scripts in their $OUTPUTS file
+	Variables map[string]string `json:"variables,omitempty"`
+	// Violations are the payload fields not matching the JSON Schema
+	Violations []Violation `json:"violations,omitempty"`
+	Error      error       `json:"error,omitempty"`
 }
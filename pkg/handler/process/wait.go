@@ -0,0 +1,91 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/w6d-io/process-rest/internal/process"
+)
+
+// Wait responds with the result of the job received on done. The job keeps
+// running when the timeout expires and the response is then 202
+func (h *Handler) Wait(c *gin.Context, ID string, done <-chan error, timeout time.Duration) {
+	select {
+	case err := <-done:
+		job, _ := h.executor.GetJob(ID)
+		if err == nil {
+			c.JSON(http.StatusOK, Response{Message: "process succeeded", Status: "succeed", ID: ID, Outputs: job.Outputs, Variables: job.Variables})
+			return
+		}
+		response := Response{Status: "error", Message: err.Error(), ID: ID, Outputs: job.Outputs, Variables: job.Variables}
+		code := http.StatusInternalServerError
+		var e *process.Error
+		if errors.As(err, &e) {
+			response.Stage = e.GetStage()
+			response.Code = e.GetStatusCode()
+			code = GetHTTPStatus(e.GetStatusCode())
+		}
+		if TimedOut(err) {
+			code = http.StatusGatewayTimeout
+		}
+		c.JSON(code, response)
+	case <-time.After(timeout):
+		c.JSON(http.StatusAccepted, Response{Message: "processing...", Status: "succeed", ID: ID})
+	}
+}
+
+// GetWaitOptions returns whether the request waits for the job and for how long
+func GetWaitOptions(c *gin.Context) (bool, time.Duration, error) {
+	wait, err := strconv.ParseBool(c.DefaultQuery("wait", "false"))
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid wait parameter: %w", err)
+	}
+	timeout := WaitTimeout
+	if t := c.Query("timeout"); t != "" {
+		timeout, err = time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			return false, 0, fmt.Errorf("invalid timeout parameter %q", t)
+		}
+	}
+	return wait, timeout, nil
+}
+
+// TimedOut returns whether the job failed on its job or script timeout
+func TimedOut(err error) bool {
+	var timeoutErr *process.TimeoutError
+	return errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// GetHTTPStatus maps the stage error code onto an http status
+func GetHTTPStatus(code int) int {
+	switch code {
+	case process.CodePreProcess:
+		return http.StatusFailedDependency
+	case process.CodeCancelled:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
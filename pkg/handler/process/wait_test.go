@@ -0,0 +1,159 @@
+/*
+Copyright 2020 WILDCARD
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+Created on 18/10/2026
+*/
+
+package process_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/framer"
+
+	"github.com/w6d-io/process-rest/internal/config"
+	internal "github.com/w6d-io/process-rest/internal/process"
+	"github.com/w6d-io/process-rest/pkg/handler/process"
+)
+
+var _ = Describe("Wait", func() {
+	var (
+		dir      string
+		err      error
+		executor *internal.Executor
+	)
+	post := func(query string) *httptest.ResponseRecorder {
+		payload := `{"global": { "label": "test-integration" }}`
+		r := io.NopCloser(strings.NewReader(payload))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		URL, err := url.Parse("http://localhost:8888/process?" + query)
+		Expect(err).To(Succeed())
+		executor = newExecutor(config.Config{
+			PreScriptFolder:  filepath.Join(dir, "pre"),
+			MainScriptFolder: filepath.Join(dir, "main"),
+		})
+		h := process.NewHandler(executor)
+		c.Request = &http.Request{
+			Body: framer.NewJSONFramedReader(r),
+			URL:  URL,
+		}
+		h.Process(c)
+		return w
+	}
+	script := func(stage, name, content string) {
+		Expect(os.WriteFile(filepath.Join(dir, stage, name), []byte(content), 0755)).To(Succeed())
+	}
+	BeforeEach(func() {
+		executor = nil
+		dir, err = os.MkdirTemp("", "wait_dir")
+		Expect(err).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "pre"), 0755)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(dir, "main"), 0755)).To(Succeed())
+	})
+	AfterEach(func() {
+		if executor != nil {
+			executor.Close()
+		}
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+	It("returns the outputs on success", func() {
+		script("main", "success.sh", "#!/bin/bash\necho done\n")
+		w := post("wait=true")
+		Expect(w.Code).To(Equal(200))
+		response := new(process.Response)
+		Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		Expect(response.Outputs).To(HaveLen(1))
+		Expect(response.Outputs[0].Log).To(Equal("done\n"))
+	})
+	It("returns the variables written by the scripts", func() {
+		script("pre", "pre.sh", "#!/bin/bash\necho namespace=test >> $OUTPUTS\n")
+		script("main", "main.sh", "#!/bin/bash\necho -n $OUTPUT_NAMESPACE\n")
+		w := post("wait=true")
+		Expect(w.Code).To(Equal(200))
+		response := new(process.Response)
+		Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		Expect(response.Variables).To(Equal(map[string]string{"namespace": "test"}))
+		Expect(response.Outputs[1].Stdout).To(Equal("test"))
+	})
+	It("returns the failing stage", func() {
+		script("main", "fail.sh", "#!/bin/bash\nexit 1\n")
+		w := post("wait=true")
+		Expect(w.Code).To(Equal(500))
+		response := new(process.Response)
+		Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		Expect(response.Stage).To(Equal("main"))
+		Expect(response.Code).To(Equal(internal.CodeMainProcess))
+	})
+	It("maps pre script failure onto 424", func() {
+		script("pre", "fail.sh", "#!/bin/bash\nexit 1\n")
+		script("main", "success.sh", "#!/bin/bash\necho done\n")
+		w := post("wait=true")
+		Expect(w.Code).To(Equal(424))
+	})
+	It("returns 202 when the timeout expires", func() {
+		script("main", "sleep.sh", "#!/bin/bash\nsleep 1\n")
+		w := post("wait=true&timeout=100ms")
+		Expect(w.Code).To(Equal(202))
+	})
+	It("returns the timed out script", func() {
+		script("main", "sleep.sh", "#!/bin/bash\nsleep 5\n")
+		w := post("wait=true&script_timeout=100ms")
+		Expect(w.Code).To(Equal(504))
+		response := new(process.Response)
+		Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		Expect(response.Outputs[0].Status).To(Equal(internal.StatusTimedOut))
+	})
+	It("returns 504 when the job times out", func() {
+		script("main", "sleep.sh", "#!/bin/bash\nsleep 5\n")
+		w := post("wait=true&job_timeout=100ms")
+		Expect(w.Code).To(Equal(504))
+		response := new(process.Response)
+		Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		Expect(response.Status).To(Equal("error"))
+	})
+	It("detects the timeout errors", func() {
+		Expect(process.TimedOut(&internal.TimeoutError{Script: "a.sh", Cause: errors.New("killed")})).To(BeTrue())
+		Expect(process.TimedOut(fmt.Errorf("job: %w", context.DeadlineExceeded))).To(BeTrue())
+		Expect(process.TimedOut(errors.New("exit status 1"))).To(BeFalse())
+	})
+	It("returns 400 on invalid job timeout", func() {
+		script("main", "success.sh", "#!/bin/bash\necho done\n")
+		w := post("job_timeout=never")
+		Expect(w.Code).To(Equal(400))
+	})
+	It("returns 400 on invalid timeout", func() {
+		script("main", "success.sh", "#!/bin/bash\necho done\n")
+		w := post("wait=true&timeout=never")
+		Expect(w.Code).To(Equal(400))
+	})
+	It("returns 400 on invalid wait", func() {
+		script("main", "success.sh", "#!/bin/bash\necho done\n")
+		w := post("wait=maybe")
+		Expect(w.Code).To(Equal(400))
+	})
+})
@@ -43,6 +43,11 @@ func AddGet(relativePath string, handlers ...gin.HandlerFunc) {
 	engine.GET(relativePath, handlers...)
 }
 
+// AddDelete binds a function/method to a relative path in DELETE http method
+func AddDelete(relativePath string, handlers ...gin.HandlerFunc) {
+	engine.DELETE(relativePath, handlers...)
+}
+
 func SetListen(address string) {
 	server.Addr = address
 }
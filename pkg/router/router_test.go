@@ -37,6 +37,9 @@ var _ = Describe("Router", func() {
 		It("add a get handler", func() {
 			router.AddGet("/test/unit", func(c *gin.Context) {})
 		})
+		It("add a delete handler", func() {
+			router.AddDelete("/test/unit", func(c *gin.Context) {})
+		})
 		It("set listen", func() {
 			router.SetListen(":8080")
 		})